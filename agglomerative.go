@@ -0,0 +1,262 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type (
+	//Linkage defines the distance between two clusters
+	Linkage int
+
+	//Merge joins the clusters Left and Right at Distance into a cluster of Size rows
+	//clusters 1...n are the single rows and n+i is the cluster created by the i-th merge
+	Merge struct {
+		Left, Right int
+		Distance    float64
+		Size        int
+	}
+
+	//Dendrogram is the full merge history of n rows into a single cluster
+	//the merges are ordered by their distance
+	Dendrogram struct {
+		NumLeaves int
+		Merges    []Merge
+	}
+
+	//Agglomerative merges the two closest clusters until K clusters are left
+	//the distance of rows is euclidean, the distance of clusters is defined by Linkage
+	Agglomerative struct {
+		K       int
+		Linkage Linkage
+
+		dendrogram *Dendrogram
+		labels     *Vector
+	}
+)
+
+const (
+	//distance of the closest rows of both clusters
+	SingleLinkage Linkage = iota
+	//distance of the farthest rows of both clusters
+	CompleteLinkage
+	//mean distance of all pairs of rows of both clusters
+	AverageLinkage
+	//increase of the within-cluster variance caused by the merge
+	WardLinkage
+)
+
+func (l Linkage) String() string {
+	switch l {
+	case SingleLinkage:
+		return "single"
+	case CompleteLinkage:
+		return "complete"
+	case AverageLinkage:
+		return "average"
+	case WardLinkage:
+		return "ward"
+	}
+	return fmt.Sprintf("Linkage(%d)", int(l))
+}
+
+func NewAgglomerative(k int, linkage Linkage) (*Agglomerative, error) {
+	a := &Agglomerative{K: k, Linkage: linkage}
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *Agglomerative) validate() error {
+	if a.K < 1 {
+		return fmt.Errorf("K should be at least 1")
+	}
+
+	if a.Linkage < SingleLinkage || a.Linkage > WardLinkage {
+		return fmt.Errorf("Unknown linkage %v", a.Linkage)
+	}
+
+	return nil
+}
+
+//build the dendrogram of the rows of x and cut it into K clusters
+func (a *Agglomerative) Fit(x *Matrix) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+
+	if err := validateClusterInput(x, a.K); err != nil {
+		return err
+	}
+
+	a.dendrogram = buildDendrogram(x.rowSlices(), a.Linkage)
+	labels, err := a.dendrogram.Cut(a.K)
+	if err != nil {
+		return err
+	}
+
+	a.labels = labels
+	return nil
+}
+
+//fit x and return the labels of its rows
+func (a *Agglomerative) FitPredict(x *Matrix) (*Vector, error) {
+	if err := a.Fit(x); err != nil {
+		return nil, err
+	}
+	return a.Labels(), nil
+}
+
+//get the labels 1...K of the rows used by Fit
+func (a *Agglomerative) Labels() *Vector {
+	if a.labels == nil {
+		return nil
+	}
+	return a.labels.Clone()
+}
+
+//get the dendrogram of the rows used by Fit
+//it can be cut into any other number of clusters without fitting again
+func (a *Agglomerative) Dendrogram() *Dendrogram { return a.dendrogram }
+
+//merge the clusters with the smallest distance until one is left
+//distances of a new cluster are derived with the Lance-Williams formula
+func buildDendrogram(rows [][]float64, linkage Linkage) *Dendrogram {
+	n := len(rows)
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+	}
+	parallelRows(n, n*len(rows[0]), func(from, to int) {
+		for i := from; i < to; i++ {
+			for j := range rows {
+				dist[i][j] = math.Sqrt(squaredDistance(rows[i], rows[j]))
+			}
+		}
+	})
+
+	//slot i holds the cluster with id ids[i] as long as it is active
+	ids := make([]int, n)
+	sizes := make([]int, n)
+	active := make([]bool, n)
+	for i := range ids {
+		ids[i], sizes[i], active[i] = i+1, 1, true
+	}
+
+	d := &Dendrogram{NumLeaves: n}
+	for step := 1; step < n; step++ {
+		a, b, best := -1, -1, math.Inf(1)
+		for i := 0; i < n; i++ {
+			if !active[i] {
+				continue
+			}
+			for j := i + 1; j < n; j++ {
+				if active[j] && dist[i][j] < best {
+					a, b, best = i, j, dist[i][j]
+				}
+			}
+		}
+
+		d.Merges = append(d.Merges, Merge{
+			Left:     minInt(ids[a], ids[b]),
+			Right:    maxInt(ids[a], ids[b]),
+			Distance: best,
+			Size:     sizes[a] + sizes[b],
+		})
+
+		//the merged cluster takes slot a
+		for k := 0; k < n; k++ {
+			if !active[k] || k == a || k == b {
+				continue
+			}
+			dist[a][k] = linkageDistance(linkage, dist[k][a], dist[k][b], best, sizes[a], sizes[b], sizes[k])
+			dist[k][a] = dist[a][k]
+		}
+
+		ids[a] = n + step
+		sizes[a] += sizes[b]
+		active[b] = false
+	}
+
+	return d
+}
+
+//distance from cluster k to the union of i and j
+func linkageDistance(linkage Linkage, dki, dkj, dij float64, ni, nj, nk int) float64 {
+	switch linkage {
+	case SingleLinkage:
+		return math.Min(dki, dkj)
+	case CompleteLinkage:
+		return math.Max(dki, dkj)
+	case AverageLinkage:
+		return (float64(ni)*dki + float64(nj)*dkj) / float64(ni+nj)
+	}
+
+	//ward
+	fi, fj, fk := float64(ni), float64(nj), float64(nk)
+	sq := ((fk+fi)*dki*dki + (fk+fj)*dkj*dkj - fk*dij*dij) / (fi + fj + fk)
+	return math.Sqrt(math.Max(sq, 0))
+}
+
+//label every row with its cluster after applying the first merges
+//clusters are numbered 1, 2, ... in the order of their first row
+func (d *Dendrogram) labels(numMerges int) *Vector {
+	n := d.NumLeaves
+	parent := make([]int, n+numMerges+1)
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i, m := range d.Merges[:numMerges] {
+		parent[find(m.Left)] = n + i + 1
+		parent[find(m.Right)] = n + i + 1
+	}
+
+	result := NewZeroVector(n)
+	clusters := map[int]float64{}
+	for i := 1; i <= n; i++ {
+		root := find(i)
+		if _, ok := clusters[root]; !ok {
+			clusters[root] = float64(len(clusters) + 1)
+		}
+		result.val[i-1] = clusters[root]
+	}
+	return result
+}
+
+//get the labels 1...k of the rows when the dendrogram is cut into k clusters
+func (d *Dendrogram) Cut(k int) (*Vector, error) {
+	if k < 1 || k > d.NumLeaves {
+		return nil, fmt.Errorf("Number of clusters should be in range [1, %d]", d.NumLeaves)
+	}
+
+	return d.labels(d.NumLeaves - k), nil
+}
+
+//get the labels of the rows when only merges up to threshold distance are applied
+func (d *Dendrogram) CutDistance(threshold float64) *Vector {
+	numMerges := 0
+	for numMerges < len(d.Merges) && d.Merges[numMerges].Distance <= threshold {
+		numMerges++
+	}
+	return d.labels(numMerges)
+}
+
+func (d *Dendrogram) String() string {
+	var sb strings.Builder
+	for i, m := range d.Merges {
+		fmt.Fprintf(&sb, "%d: %d + %d -> %d (distance %.5f, size %d)\n",
+			i+1, m.Left, m.Right, d.NumLeaves+i+1, m.Distance, m.Size)
+	}
+	return sb.String()
+}
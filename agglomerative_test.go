@@ -0,0 +1,83 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDendrogram(t *testing.T) {
+	rows := [][]float64{{0}, {1}, {5}, {7}}
+
+	single := buildDendrogram(rows, SingleLinkage)
+	assert.Equal(t, []Merge{
+		{Left: 1, Right: 2, Distance: 1, Size: 2},
+		{Left: 3, Right: 4, Distance: 2, Size: 2},
+		{Left: 5, Right: 6, Distance: 4, Size: 4},
+	}, single.Merges)
+	fmt.Print(single)
+
+	complete := buildDendrogram(rows, CompleteLinkage)
+	assert.Equal(t, float64(7), complete.Merges[2].Distance)
+
+	average := buildDendrogram(rows, AverageLinkage)
+	//mean of 5, 7, 4, 6
+	assert.Equal(t, 5.5, average.Merges[2].Distance)
+
+	//ward distance of two clusters is sqrt(2 * na * nb / (na + nb)) * |ca - cb|
+	ward := buildDendrogram(rows, WardLinkage)
+	assert.InDelta(t, 1, ward.Merges[0].Distance, 1e-12)
+	assert.InDelta(t, 2, ward.Merges[1].Distance, 1e-12)
+	assert.InDelta(t, 5.5*math.Sqrt2, ward.Merges[2].Distance, 1e-12)
+}
+
+func TestDendrogramCut(t *testing.T) {
+	d := buildDendrogram([][]float64{{0}, {5}, {1}, {7}}, SingleLinkage)
+
+	labels, err := d.Cut(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 1, 2}, labels.val)
+
+	labels, _ = d.Cut(4)
+	assert.Equal(t, []float64{1, 2, 3, 4}, labels.val)
+
+	labels, _ = d.Cut(1)
+	assert.Equal(t, []float64{1, 1, 1, 1}, labels.val)
+
+	_, err = d.Cut(5)
+	assert.Error(t, err)
+
+	assert.Equal(t, []float64{1, 2, 1, 3}, d.CutDistance(1.5).val)
+	assert.Equal(t, []float64{1, 2, 3, 4}, d.CutDistance(0.5).val)
+}
+
+func TestAgglomerative(t *testing.T) {
+	_, err := NewAgglomerative(0, SingleLinkage)
+	assert.Error(t, err)
+
+	_, err = NewAgglomerative(2, Linkage(10))
+	assert.Error(t, err)
+
+	x, y := newBlobs(blobCenters, 30, 1, 1)
+	for _, linkage := range []Linkage{SingleLinkage, CompleteLinkage, AverageLinkage, WardLinkage} {
+		a, _ := NewAgglomerative(3, linkage)
+		labels, err := a.FitPredict(x)
+		assert.NoError(t, err, linkage.String())
+		assertSameClusters(t, labels, y)
+
+		d := a.Dendrogram()
+		assert.Equal(t, 89, len(d.Merges))
+		assert.Equal(t, 90, d.Merges[88].Size)
+
+		//merge distances never decrease
+		for i := 1; i < len(d.Merges); i++ {
+			assert.True(t, d.Merges[i].Distance >= d.Merges[i-1].Distance-1e-9)
+		}
+	}
+
+	a, _ := NewAgglomerative(3, WardLinkage)
+	assert.Nil(t, a.Labels())
+	assert.Error(t, a.Fit(NewConstantMatrix(2, 2, 0)))
+}
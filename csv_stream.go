@@ -0,0 +1,150 @@
+package ml
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+type (
+	//CSVStream reads a csv file row by row instead of loading it at once
+	//so files bigger than the memory can be used for training
+	CSVStream struct {
+		file   *os.File
+		reader *csv.Reader
+		row    int
+	}
+)
+
+//open a csv file for streaming
+//the file should support csv formatting with comma and all string should be parseable into float64
+func OpenCSVStream(fileName string) (*CSVStream, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &CSVStream{file: f}
+	s.resetReader()
+	return s, nil
+}
+
+func (s *CSVStream) resetReader() {
+	s.reader = csv.NewReader(s.file)
+	s.reader.ReuseRecord = true
+	s.row = 0
+}
+
+//get the number of rows read since the last reset
+func (s *CSVStream) GetRowNumber() int { return s.row }
+
+//read the next row, return io.EOF if there are no more rows
+func (s *CSVStream) Next() ([]float64, error) {
+	rec, err := s.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	s.row++
+	result := make([]float64, 0, len(rec))
+	for _, str := range rec {
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: %s", s.row, err)
+		}
+		result = append(result, f)
+	}
+
+	return result, nil
+}
+
+//read up to size rows and select the columns of x and y from them
+//col syntax is the same as for LoadNewMatrix, yCol should select a single column
+//the last batch may be smaller, return io.EOF if there are no more rows
+func (s *CSVStream) NextBatch(size int, xCol, yCol string) (*Matrix, *Vector, error) {
+	if size < 1 {
+		return nil, nil, fmt.Errorf("Batch size should be at least 1")
+	}
+
+	var rows [][]float64
+	for len(rows) < size {
+		row, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, nil, io.EOF
+	}
+
+	xVal, err := filterInputByCat(rows, ":", xCol)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	yVal, err := filterInputByCat(rows, ":", yCol)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(yVal[0]) != 1 {
+		return nil, nil, fmt.Errorf("Vector should have exactly 1 column")
+	}
+
+	var x Matrix
+	x.setValue(xVal)
+
+	y := NewZeroVector(len(yVal))
+	for key, f := range yVal {
+		y.val[key] = f[0]
+	}
+
+	return &x, y, nil
+}
+
+//start reading from the first row again
+func (s *CSVStream) Reset() error {
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	s.resetReader()
+	return nil
+}
+
+func (s *CSVStream) Close() error {
+	return s.file.Close()
+}
+
+//call step for every batch of the stream, starting from the first row
+//for as many epochs
+func streamBatches(s *CSVStream, xCol, yCol string, batchSize, epochs int, step func(*Matrix, *Vector) error) error {
+	for e := 0; e < epochs; e++ {
+		if err := s.Reset(); err != nil {
+			return err
+		}
+
+		for {
+			x, y, err := s.NextBatch(batchSize, xCol, yCol)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := step(x, y); err != nil {
+				return fmt.Errorf("Epoch %d: %s", e+1, err)
+			}
+		}
+	}
+
+	return nil
+}
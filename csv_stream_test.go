@@ -0,0 +1,175 @@
+package ml
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVStream(t *testing.T) {
+	_, err := OpenCSVStream("nofile.csv")
+	assert.Error(t, err)
+
+	s, err := OpenCSVStream("data1.csv")
+	assert.NoError(t, err)
+	defer s.Close()
+
+	row, err := s.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(row))
+	assert.Equal(t, float64(0), row[2])
+
+	_, _, err = s.NextBatch(0, "1:2", "3")
+	assert.Error(t, err)
+
+	//the remaining 99 rows in batches of 40
+	var sizes []int
+	for {
+		x, y, err := s.NextBatch(40, "1:2", "3")
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, 2, x.GetColumnNumber())
+		assert.Equal(t, x.GetRowNumber(), y.GetLength())
+		sizes = append(sizes, y.GetLength())
+	}
+	assert.Equal(t, []int{40, 40, 19}, sizes)
+	assert.Equal(t, 100, s.GetRowNumber())
+
+	//reading again from the beginning gives the same rows as LoadNewMatrix
+	assert.NoError(t, s.Reset())
+	x, y, err := s.NextBatch(10, "1:2", "3")
+	assert.NoError(t, err)
+	xload, _ := LoadNewMatrix("data1.csv", "1:10", "1:2")
+	yload, _ := LoadNewVector("data1.csv", "1:10", "3")
+	assert.Equal(t, xload, x)
+	assert.Equal(t, yload, y)
+
+	_, _, err = s.NextBatch(10, "1:2", "2:3")
+	assert.Error(t, err)
+}
+
+func TestCSVStreamInvalidValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mlstream")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "invalid.csv")
+	ioutil.WriteFile(fileName, []byte("1,2\n3,a\n"), 0644)
+
+	s, err := OpenCSVStream(fileName)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	_, _, err = s.NextBatch(10, "1", "2")
+	assert.Error(t, err)
+}
+
+//write n rows of x1, x2 uniform in [0, 1) and y = f(x1, x2) to a temporary csv file
+//the rows are only ever read through a CSVStream
+func writeStreamData(t *testing.T, dir string, n int, f func(x1, x2 float64) float64) string {
+	rnd := rand.New(rand.NewSource(1))
+
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		x1, x2 := rnd.Float64(), rnd.Float64()
+		fmt.Fprintf(&buf, "%v,%v,%v\n", x1, x2, f(x1, x2))
+	}
+
+	fileName := filepath.Join(dir, "stream.csv")
+	assert.NoError(t, ioutil.WriteFile(fileName, buf.Bytes(), 0644))
+	return fileName
+}
+
+func TestLinearRegressionUpdateGradStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mlstream")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, _ := OpenCSVStream(writeStreamData(t, dir, 2000, func(x1, x2 float64) float64 { return 2 + 3*x1 - x2 }))
+	defer s.Close()
+
+	_, err = NewOnlineLinearRegression(NewZeroVector(0), 0.1)
+	assert.Error(t, err)
+	_, err = NewOnlineLinearRegression(NewZeroVector(3), 0)
+	assert.Error(t, err)
+
+	lr, err := NewOnlineLinearRegression(NewZeroVector(3), 0.5)
+	assert.NoError(t, err)
+	assert.NoError(t, lr.SetMomentum(0.5))
+
+	//the first batch is used to measure the cost
+	bx, by, _ := s.NextBatch(100, "1:2", "3")
+	cost, err := lr.BatchCost(bx, by)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, bx.GetColumnNumber())
+
+	err = lr.UpdateGradStream(s, "1:2", "3", 32, 10)
+	assert.NoError(t, err)
+	fmt.Printf("Linear regression theta after streaming: %s\n", lr.theta)
+	assert.InDeltaSlice(t, []float64{2, 3, -1}, lr.theta.val, 0.05)
+
+	after, _ := lr.BatchCost(bx, by)
+	assert.True(t, after < cost)
+
+	//the model never holds a batch, so there is nothing for full batch training
+	assert.Nil(t, lr.x)
+	assert.True(t, math.IsNaN(lr.CostFunc()))
+	assert.Nil(t, lr.CalculateGrad())
+	_, err = lr.UpdateGradContext(context.Background(), 10)
+	assert.Equal(t, ErrNoTrainingData, err)
+	_, err = CheckGradient(lr, 0)
+	assert.Equal(t, ErrNoTrainingData, err)
+
+	//dimensions of the batches should agree with theta
+	err = lr.UpdateGradStream(s, "1", "3", 16, 1)
+	assert.Error(t, err)
+	_, err = lr.BatchCost(bx, NewZeroVector(1))
+	assert.Error(t, err)
+}
+
+func TestLogisticRegressionUpdateGradStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mlstream")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, _ := OpenCSVStream(writeStreamData(t, dir, 2000, func(x1, x2 float64) float64 {
+		if x1+x2 > 1 {
+			return 1
+		}
+		return 0
+	}))
+	defer s.Close()
+
+	lreg, err := NewOnlineLogisticRegression(NewZeroVector(3), 1)
+	assert.NoError(t, err)
+
+	bx, by, _ := s.NextBatch(200, "1:2", "3")
+	cost, _ := lreg.BatchCost(bx, by)
+
+	err = lreg.UpdateGradStream(s, "1:2", "3", 50, 10, false)
+	assert.NoError(t, err)
+
+	after, _ := lreg.BatchCost(bx, by)
+	bx.AddConstantVectorToFirst(1)
+	result, _ := lreg.CalculateResult(bx)
+	acc, _ := Accuracy(result, by)
+	fmt.Printf("Logistic regression cost before %v after streaming %v, accuracy %v\n", cost, after, acc)
+	assert.True(t, after < cost)
+	assert.True(t, acc > 0.9)
+	assert.Nil(t, lreg.x)
+
+	//labels are validated for every batch
+	err = lreg.UpdateGradStream(s, "2:3", "1", 25, 1, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package ml
+
+import "fmt"
+
+type (
+	//DBSCAN groups rows which are densely packed together
+	//a row with at least MinPts rows (itself included) within Eps is a core row,
+	//clusters are the core rows reachable from each other plus their neighbors
+	//rows which don't belong to any cluster are labelled as NoiseLabel
+	DBSCAN struct {
+		Eps    float64
+		MinPts int
+		Metric DistanceMetric
+		P      float64
+
+		labels []int
+		core   []int
+	}
+)
+
+//label of rows which don't belong to any cluster
+const NoiseLabel = -1
+
+//create DBSCAN with the euclidean metric
+func NewDBSCAN(eps float64, minPts int) (*DBSCAN, error) {
+	d := &DBSCAN{Eps: eps, MinPts: minPts, Metric: EuclideanMetric, P: 2}
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *DBSCAN) validate() error {
+	if d.Eps <= 0 {
+		return fmt.Errorf("Eps should be greater than 0")
+	}
+
+	if d.MinPts < 1 {
+		return fmt.Errorf("MinPts should be at least 1")
+	}
+
+	return validateMetric(d.Metric, d.P)
+}
+
+//label the rows of x with the clusters 1, 2, ... or NoiseLabel
+//clusters are numbered in the order of their first core row
+func (d *DBSCAN) Fit(x *Matrix) error {
+	if err := d.validate(); err != nil {
+		return err
+	}
+
+	index, err := newNeighborIndex(x, d.Metric, d.P, false)
+	if err != nil {
+		return err
+	}
+
+	n := len(index.rows)
+	neighbors := make([][]neighbor, n)
+	parallelRows(n, n, func(from, to int) {
+		for i := from; i < to; i++ {
+			neighbors[i] = index.queryRadius(index.rows[i], d.Eps)
+		}
+	})
+
+	labels := make([]int, n)
+	for i := range labels {
+		labels[i] = NoiseLabel
+	}
+
+	var core []int
+	isCore := func(i int) bool { return len(neighbors[i]) >= d.MinPts }
+
+	cluster := 0
+	for i := 0; i < n; i++ {
+		if labels[i] != NoiseLabel || !isCore(i) {
+			continue
+		}
+
+		//expand the new cluster from core row i
+		cluster++
+		labels[i] = cluster
+		queue := []int{i}
+		for len(queue) > 0 {
+			j := queue[0]
+			queue = queue[1:]
+			if !isCore(j) {
+				continue
+			}
+
+			for _, nb := range neighbors[j] {
+				k := nb.index - 1
+				if labels[k] == NoiseLabel {
+					labels[k] = cluster
+					queue = append(queue, k)
+				}
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if isCore(i) {
+			core = append(core, i+1)
+		}
+	}
+
+	d.labels, d.core = labels, core
+	return nil
+}
+
+//fit x and return the labels of its rows
+func (d *DBSCAN) FitPredict(x *Matrix) (*Vector, error) {
+	if err := d.Fit(x); err != nil {
+		return nil, err
+	}
+	return d.Labels(), nil
+}
+
+//get the labels of the rows used by Fit, noise is NoiseLabel
+func (d *DBSCAN) Labels() *Vector {
+	if d.labels == nil {
+		return nil
+	}
+
+	result := NewZeroVector(len(d.labels))
+	for i, l := range d.labels {
+		result.val[i] = float64(l)
+	}
+	return result
+}
+
+//get the 1-based indices of the core rows
+func (d *DBSCAN) CoreSamples() []int {
+	result := make([]int, len(d.core))
+	copy(result, d.core)
+	return result
+}
+
+//get the number of clusters without noise
+func (d *DBSCAN) NumClusters() int {
+	result := 0
+	for _, l := range d.labels {
+		result = maxInt(result, l)
+	}
+	return result
+}
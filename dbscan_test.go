@@ -0,0 +1,71 @@
+package ml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBSCAN(t *testing.T) {
+	_, err := NewDBSCAN(0, 3)
+	assert.Error(t, err)
+
+	_, err = NewDBSCAN(1, 0)
+	assert.Error(t, err)
+
+	x, _ := NewMatrix([][]float64{
+		{0, 0}, {0, 1}, {1, 0}, {1, 1},
+		{2.2, 1},
+		{10, 10}, {10, 11}, {11, 10},
+		{5, 20},
+	})
+
+	d, _ := NewDBSCAN(1.5, 3)
+	labels, err := d.FitPredict(x)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 1, 1, 1, 1, 2, 2, 2, NoiseLabel}, labels.val)
+	assert.Equal(t, 2, d.NumClusters())
+	//{2.2, 1} only has {1, 1} within eps so it is a border row
+	assert.Equal(t, []int{1, 2, 3, 4, 6, 7, 8}, d.CoreSamples())
+
+	//with a greater MinPts the small cluster becomes noise
+	d.MinPts = 4
+	labels, _ = d.FitPredict(x)
+	assert.Equal(t, []float64{1, 1, 1, 1, 1, NoiseLabel, NoiseLabel, NoiseLabel, NoiseLabel}, labels.val)
+
+	d.Metric = MinkowskiMetric
+	d.P = 0
+	assert.Error(t, d.Fit(x))
+}
+
+func TestDBSCANBlobs(t *testing.T) {
+	x, y := newBlobs(blobCenters, 100, 1, 1)
+	//far away outliers
+	x.AddRowVector(NewVector([]float64{50, 50}))
+	x.AddRowVector(NewVector([]float64{-50, -50}))
+
+	d, _ := NewDBSCAN(1.5, 5)
+	labels, err := d.FitPredict(x)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, d.NumClusters())
+	assert.Equal(t, float64(NoiseLabel), labels.getSingleValue(301))
+	assert.Equal(t, float64(NoiseLabel), labels.getSingleValue(302))
+
+	//most rows of a blob are in the same cluster
+	for blob := 1; blob <= 3; blob++ {
+		counts := map[float64]int{}
+		for i := 1; i <= y.GetLength(); i++ {
+			if y.getSingleValue(i) == float64(blob) {
+				counts[labels.getSingleValue(i)]++
+			}
+		}
+
+		best := 0
+		for label, c := range counts {
+			if label != NoiseLabel && c > best {
+				best = c
+			}
+		}
+		assert.True(t, best > 90)
+	}
+}
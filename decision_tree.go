@@ -0,0 +1,717 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+type (
+	//Criterion measures the impurity of the rows of a tree node
+	Criterion int
+
+	//treeNode is a leaf if left and right are nil
+	//value holds the class probabilities of a classifier or the prediction of a regressor
+	treeNode struct {
+		feature     int
+		threshold   float64
+		left, right *treeNode
+		value       []float64
+		impurity    float64
+		samples     int
+	}
+
+	//treeParams are the stopping rules of the tree growth
+	//maxDepth and maxFeatures 0 mean unlimited
+	treeParams struct {
+		criterion       Criterion
+		maxDepth        int
+		minSamplesSplit int
+		minSamplesLeaf  int
+		maxFeatures     int
+	}
+
+	//treeBuilder grows a tree over the given rows
+	//y are class indices 0...numClasses-1 for classification and the targets for regression
+	treeBuilder struct {
+		treeParams
+		rows        [][]float64
+		y           []float64
+		numClasses  int
+		rnd         *rand.Rand
+		importances []float64
+	}
+
+	//impurityCounter tracks the impurity of both sides while rows move from right to left
+	impurityCounter interface {
+		reset(idx []int)
+		move(i int)
+		impurities() (left, right float64)
+	}
+
+	classCounter struct {
+		y           []float64
+		left, right []float64
+		nl, nr      float64
+		criterion   Criterion
+	}
+
+	varianceCounter struct {
+		y                    []float64
+		sumL, sumR, sqL, sqR float64
+		nl, nr               float64
+	}
+
+	//absolute error has no running update, the sides are kept and sorted when needed
+	maeCounter struct {
+		y           []float64
+		left, right []float64
+	}
+
+	//DecisionTreeClassifier is a CART tree for classification with Gini or entropy criterion
+	//MaxDepth 0 means unlimited, MaxFeatures 0 means every column is tried at every split,
+	//otherwise MaxFeatures random columns drawn with Seed
+	DecisionTreeClassifier struct {
+		Criterion       Criterion
+		MaxDepth        int
+		MinSamplesSplit int
+		MinSamplesLeaf  int
+		MaxFeatures     int
+		Seed            int64
+
+		root        *treeNode
+		classes     []float64
+		numFeatures int
+		importances []float64
+	}
+
+	//DecisionTreeRegressor is a CART tree for regression with MSE or MAE criterion
+	//the parameters are the same as of DecisionTreeClassifier
+	DecisionTreeRegressor struct {
+		Criterion       Criterion
+		MaxDepth        int
+		MinSamplesSplit int
+		MinSamplesLeaf  int
+		MaxFeatures     int
+		Seed            int64
+
+		root        *treeNode
+		numFeatures int
+		importances []float64
+	}
+)
+
+const (
+	//gini impurity is 1 - sigma(pi^2)
+	GiniCriterion Criterion = iota
+	//entropy is -sigma(pi * log2(pi))
+	EntropyCriterion
+	//mean squared error to the mean, leaves predict the mean
+	MSECriterion
+	//mean absolute error to the median, leaves predict the median
+	MAECriterion
+)
+
+func (c Criterion) String() string {
+	switch c {
+	case GiniCriterion:
+		return "gini"
+	case EntropyCriterion:
+		return "entropy"
+	case MSECriterion:
+		return "mse"
+	case MAECriterion:
+		return "mae"
+	}
+	return fmt.Sprintf("Criterion(%d)", int(c))
+}
+
+func (c Criterion) isClassification() bool {
+	return c == GiniCriterion || c == EntropyCriterion
+}
+
+//create a classification tree with at least 2 rows per split and 1 per leaf
+func NewDecisionTreeClassifier(criterion Criterion, maxDepth int) (*DecisionTreeClassifier, error) {
+	t := &DecisionTreeClassifier{Criterion: criterion, MaxDepth: maxDepth, MinSamplesSplit: 2, MinSamplesLeaf: 1}
+	if err := t.params().validate(true); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+//create a regression tree with at least 2 rows per split and 1 per leaf
+func NewDecisionTreeRegressor(criterion Criterion, maxDepth int) (*DecisionTreeRegressor, error) {
+	t := &DecisionTreeRegressor{Criterion: criterion, MaxDepth: maxDepth, MinSamplesSplit: 2, MinSamplesLeaf: 1}
+	if err := t.params().validate(false); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (p treeParams) validate(classification bool) error {
+	if p.criterion < GiniCriterion || p.criterion > MAECriterion {
+		return fmt.Errorf("Unknown criterion %v", p.criterion)
+	}
+
+	if p.criterion.isClassification() != classification {
+		return fmt.Errorf("Criterion %v can't be used for this tree", p.criterion)
+	}
+
+	if p.maxDepth < 0 {
+		return fmt.Errorf("MaxDepth should not be negative")
+	}
+
+	if p.minSamplesSplit < 2 {
+		return fmt.Errorf("MinSamplesSplit should be at least 2")
+	}
+
+	if p.minSamplesLeaf < 1 {
+		return fmt.Errorf("MinSamplesLeaf should be at least 1")
+	}
+
+	if p.maxFeatures < 0 {
+		return fmt.Errorf("MaxFeatures should not be negative")
+	}
+
+	return nil
+}
+
+///////////////////////////
+////////BUILDER///////////
+//////////////////////////
+
+func newTreeBuilder(p treeParams, rows [][]float64, y []float64, numClasses int, rnd *rand.Rand) *treeBuilder {
+	return &treeBuilder{
+		treeParams:  p,
+		rows:        rows,
+		y:           y,
+		numClasses:  numClasses,
+		rnd:         rnd,
+		importances: make([]float64, len(rows[0])),
+	}
+}
+
+func (b *treeBuilder) newCounter() impurityCounter {
+	switch b.criterion {
+	case GiniCriterion, EntropyCriterion:
+		return &classCounter{y: b.y, criterion: b.criterion,
+			left: make([]float64, b.numClasses), right: make([]float64, b.numClasses)}
+	case MSECriterion:
+		return &varianceCounter{y: b.y}
+	}
+	return &maeCounter{y: b.y}
+}
+
+//value of a leaf with the rows idx
+func (b *treeBuilder) leafValue(idx []int) []float64 {
+	if b.criterion.isClassification() {
+		result := make([]float64, b.numClasses)
+		for _, i := range idx {
+			result[int(b.y[i])]++
+		}
+		for c := range result {
+			result[c] /= float64(len(idx))
+		}
+		return result
+	}
+
+	val := make([]float64, len(idx))
+	for k, i := range idx {
+		val[k] = b.y[i]
+	}
+	if b.criterion == MAECriterion {
+		return []float64{median(val)}
+	}
+	return []float64{mean(NewVector(val))}
+}
+
+//grow the tree over the 0-based row indices idx, indices may repeat e.g. for bootstrap samples
+//the importance of a feature is the weighted impurity decrease of its splits
+func (b *treeBuilder) build(idx []int, depth int) *treeNode {
+	counter := b.newCounter()
+	counter.reset(idx)
+	_, impurity := counter.impurities()
+
+	node := &treeNode{feature: -1, value: b.leafValue(idx), impurity: impurity, samples: len(idx)}
+	if impurity <= 0 || len(idx) < b.minSamplesSplit || len(idx) < 2*b.minSamplesLeaf ||
+		(b.maxDepth > 0 && depth >= b.maxDepth) {
+		return node
+	}
+
+	feature, threshold, childImpurity, ok := b.bestSplit(idx, counter)
+	if !ok {
+		return node
+	}
+
+	var left, right []int
+	for _, i := range idx {
+		if b.rows[i][feature] <= threshold {
+			left = append(left, i)
+		} else {
+			right = append(right, i)
+		}
+	}
+
+	b.importances[feature] += float64(len(idx))*impurity - childImpurity
+	node.feature, node.threshold = feature, threshold
+	node.left = b.build(left, depth+1)
+	node.right = b.build(right, depth+1)
+	return node
+}
+
+//columns tried at a split, a random subset if maxFeatures is set
+func (b *treeBuilder) candidateFeatures() []int {
+	n := len(b.rows[0])
+	if b.maxFeatures == 0 || b.maxFeatures >= n {
+		result := make([]int, n)
+		for i := range result {
+			result[i] = i
+		}
+		return result
+	}
+
+	result := b.rnd.Perm(n)[:b.maxFeatures]
+	sort.Ints(result)
+	return result
+}
+
+//find the split with the lowest sum of impurity * rows of both sides
+//thresholds are the midpoints between distinct values, both sides keep at least minSamplesLeaf rows
+func (b *treeBuilder) bestSplit(idx []int, counter impurityCounter) (int, float64, float64, bool) {
+	feature, threshold, best := -1, 0.0, math.Inf(1)
+	sorted := make([]int, len(idx))
+	n := len(idx)
+
+	for _, f := range b.candidateFeatures() {
+		copy(sorted, idx)
+		sort.SliceStable(sorted, func(a, c int) bool { return b.rows[sorted[a]][f] < b.rows[sorted[c]][f] })
+
+		counter.reset(sorted)
+		for k := 0; k < n-1; k++ {
+			counter.move(sorted[k])
+
+			v, next := b.rows[sorted[k]][f], b.rows[sorted[k+1]][f]
+			nl := k + 1
+			if v == next || nl < b.minSamplesLeaf || n-nl < b.minSamplesLeaf {
+				continue
+			}
+
+			l, r := counter.impurities()
+			if weighted := float64(nl)*l + float64(n-nl)*r; weighted < best {
+				feature, threshold, best = f, midpoint(v, next), weighted
+			}
+		}
+	}
+
+	return feature, threshold, best, feature >= 0
+}
+
+//threshold between two adjacent distinct values so that v goes left and next goes right
+//(v+next)/2 would overflow for huge values and can round up to next for adjacent floats
+func midpoint(v, next float64) float64 {
+	mid := v + (next-v)/2
+	if mid >= next {
+		return v
+	}
+	return mid
+}
+
+func (c *classCounter) reset(idx []int) {
+	for k := range c.left {
+		c.left[k], c.right[k] = 0, 0
+	}
+	for _, i := range idx {
+		c.right[int(c.y[i])]++
+	}
+	c.nl, c.nr = 0, float64(len(idx))
+}
+
+func (c *classCounter) move(i int) {
+	c.left[int(c.y[i])]++
+	c.right[int(c.y[i])]--
+	c.nl++
+	c.nr--
+}
+
+func (c *classCounter) impurity(counts []float64, n float64) float64 {
+	if n == 0 {
+		return 0
+	}
+
+	var result float64
+	if c.criterion == GiniCriterion {
+		result = 1
+		for _, count := range counts {
+			p := count / n
+			result -= p * p
+		}
+		return result
+	}
+
+	for _, count := range counts {
+		if count > 0 {
+			p := count / n
+			result -= p * math.Log2(p)
+		}
+	}
+	return result
+}
+
+func (c *classCounter) impurities() (float64, float64) {
+	return c.impurity(c.left, c.nl), c.impurity(c.right, c.nr)
+}
+
+func (c *varianceCounter) reset(idx []int) {
+	c.sumL, c.sqL, c.nl = 0, 0, 0
+	c.sumR, c.sqR, c.nr = 0, 0, float64(len(idx))
+	for _, i := range idx {
+		c.sumR += c.y[i]
+		c.sqR += c.y[i] * c.y[i]
+	}
+}
+
+func (c *varianceCounter) move(i int) {
+	y := c.y[i]
+	c.sumL += y
+	c.sqL += y * y
+	c.nl++
+	c.sumR -= y
+	c.sqR -= y * y
+	c.nr--
+}
+
+func squaredError(sum, sq, n float64) float64 {
+	if n == 0 {
+		return 0
+	}
+	m := sum / n
+	return math.Max(sq/n-m*m, 0)
+}
+
+func (c *varianceCounter) impurities() (float64, float64) {
+	return squaredError(c.sumL, c.sqL, c.nl), squaredError(c.sumR, c.sqR, c.nr)
+}
+
+func (c *maeCounter) reset(idx []int) {
+	c.left = c.left[:0]
+	c.right = c.right[:0]
+	for _, i := range idx {
+		c.right = append(c.right, c.y[i])
+	}
+}
+
+//rows are moved in the order of reset so the first of right is the moved one
+func (c *maeCounter) move(i int) {
+	c.left = append(c.left, c.y[i])
+	c.right = c.right[1:]
+}
+
+func absoluteError(val []float64) float64 {
+	if len(val) == 0 {
+		return 0
+	}
+
+	m := median(val)
+	var result float64
+	for _, f := range val {
+		result += math.Abs(f - m)
+	}
+	return result / float64(len(val))
+}
+
+func (c *maeCounter) impurities() (float64, float64) {
+	return absoluteError(c.left), absoluteError(c.right)
+}
+
+//normalize the importances so they sum up to 1
+func normalizeImportances(importances []float64) []float64 {
+	result := copyFloats(importances)
+	var total float64
+	for _, f := range result {
+		total += f
+	}
+	if total > 0 {
+		for i := range result {
+			result[i] /= total
+		}
+	}
+	return result
+}
+
+///////////////////////////
+////////NODES/////////////
+//////////////////////////
+
+//find the leaf of row
+func (n *treeNode) apply(row []float64) *treeNode {
+	for n.left != nil {
+		if row[n.feature] <= n.threshold {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return n
+}
+
+func (n *treeNode) depth() int {
+	if n.left == nil {
+		return 0
+	}
+	return 1 + maxInt(n.left.depth(), n.right.depth())
+}
+
+func (n *treeNode) numLeaves() int {
+	if n.left == nil {
+		return 1
+	}
+	return n.left.numLeaves() + n.right.numLeaves()
+}
+
+//write the tree with one line per branch, columns are named x1...xn
+//leaf describes the value of a leaf
+func (n *treeNode) dump(sb *strings.Builder, indent string, leaf func([]float64) string) {
+	if n.left == nil {
+		fmt.Fprintf(sb, "%s|--- %s (samples: %d)\n", indent, leaf(n.value), n.samples)
+		return
+	}
+
+	fmt.Fprintf(sb, "%s|--- x%d <= %.5f\n", indent, n.feature+1, n.threshold)
+	n.left.dump(sb, indent+"|   ", leaf)
+	fmt.Fprintf(sb, "%s|--- x%d >  %.5f\n", indent, n.feature+1, n.threshold)
+	n.right.dump(sb, indent+"|   ", leaf)
+}
+
+//x should be valid and have a row per element of y
+func validateTreeInput(x *Matrix, y *Vector) error {
+	if err := x.validate(); err != nil {
+		return err
+	}
+
+	if x.GetRowNumber() != y.GetLength() {
+		return fmt.Errorf("X and Y row number are not the same")
+	}
+
+	return nil
+}
+
+//x should be valid and have as many columns as the training data
+func validateTreePredictInput(root *treeNode, numFeatures int, x *Matrix) error {
+	if root == nil {
+		return ErrNotFitted
+	}
+
+	if err := x.validate(); err != nil {
+		return err
+	}
+
+	if x.GetColumnNumber() != numFeatures {
+		return fmt.Errorf("Input dimension(%d) does not agree with training data(%d)",
+			x.GetColumnNumber(), numFeatures)
+	}
+
+	return nil
+}
+
+//0-based indices 0...n-1
+func allRows(n int) []int {
+	result := make([]int, n)
+	for i := range result {
+		result[i] = i
+	}
+	return result
+}
+
+//map every label to the index of its class
+func classIndexOf(y []float64, classes []float64) []float64 {
+	index := make(map[float64]int)
+	for i, c := range classes {
+		index[c] = i
+	}
+
+	result := make([]float64, len(y))
+	for i, label := range y {
+		result[i] = float64(index[label])
+	}
+	return result
+}
+
+///////////////////////////
+////////CLASSIFIER////////
+//////////////////////////
+
+func (t *DecisionTreeClassifier) params() treeParams {
+	return treeParams{t.Criterion, t.MaxDepth, t.MinSamplesSplit, t.MinSamplesLeaf, t.MaxFeatures}
+}
+
+func (t *DecisionTreeClassifier) Fit(x *Matrix, y *Vector) error {
+	if err := t.params().validate(true); err != nil {
+		return err
+	}
+
+	if err := validateTreeInput(x, y); err != nil {
+		return err
+	}
+
+	t.classes = uniqueSorted(y.val)
+	rows := x.rowSlices()
+	b := newTreeBuilder(t.params(), rows, classIndexOf(y.val, t.classes), len(t.classes),
+		rand.New(rand.NewSource(t.Seed)))
+
+	t.root = b.build(allRows(len(rows)), 0)
+	t.numFeatures = x.GetColumnNumber()
+	t.importances = normalizeImportances(b.importances)
+	return nil
+}
+
+//get the sorted labels seen by Fit
+func (t *DecisionTreeClassifier) Classes() []float64 { return copyFloats(t.classes) }
+
+//calculate the share of every class in the leaf of every row
+//column j of the result belongs to Classes()[j-1]
+func (t *DecisionTreeClassifier) PredictProba(x *Matrix) (*Matrix, error) {
+	if err := validateTreePredictInput(t.root, t.numFeatures, x); err != nil {
+		return nil, err
+	}
+
+	rows := x.rowSlices()
+	input := make([][]float64, len(rows))
+	for i, row := range rows {
+		input[i] = copyFloats(t.root.apply(row).value)
+	}
+	return NewMatrix(input)
+}
+
+//predict the most frequent class in the leaf of every row
+//ties are broken in favor of the smaller label
+func (t *DecisionTreeClassifier) Predict(x *Matrix) (*Vector, error) {
+	if err := validateTreePredictInput(t.root, t.numFeatures, x); err != nil {
+		return nil, err
+	}
+
+	rows := x.rowSlices()
+	result := NewZeroVector(len(rows))
+	for i, row := range rows {
+		result.val[i] = t.classes[argmax(t.root.apply(row).value)]
+	}
+	return result, nil
+}
+
+//get the share of every column in the impurity decrease of the tree, summing up to 1
+func (t *DecisionTreeClassifier) FeatureImportances() *Vector {
+	return NewVector(copyFloats(t.importances))
+}
+
+//get the number of splits on the longest path from the root to a leaf
+func (t *DecisionTreeClassifier) GetDepth() int {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.depth()
+}
+
+func (t *DecisionTreeClassifier) GetNumLeaves() int {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.numLeaves()
+}
+
+//text dump of the tree, every leaf shows its predicted class
+func (t *DecisionTreeClassifier) String() string {
+	if t.root == nil {
+		return "DecisionTreeClassifier (not fitted)\n"
+	}
+
+	var sb strings.Builder
+	t.root.dump(&sb, "", func(value []float64) string {
+		return fmt.Sprintf("class: %v", t.classes[argmax(value)])
+	})
+	return sb.String()
+}
+
+//get the 0-based index of the greatest value, the first one on ties
+func argmax(val []float64) int {
+	best := 0
+	for i, f := range val {
+		if f > val[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+///////////////////////////
+////////REGRESSOR/////////
+//////////////////////////
+
+func (t *DecisionTreeRegressor) params() treeParams {
+	return treeParams{t.Criterion, t.MaxDepth, t.MinSamplesSplit, t.MinSamplesLeaf, t.MaxFeatures}
+}
+
+func (t *DecisionTreeRegressor) Fit(x *Matrix, y *Vector) error {
+	if err := t.params().validate(false); err != nil {
+		return err
+	}
+
+	if err := validateTreeInput(x, y); err != nil {
+		return err
+	}
+
+	rows := x.rowSlices()
+	b := newTreeBuilder(t.params(), rows, y.val, 0, rand.New(rand.NewSource(t.Seed)))
+
+	t.root = b.build(allRows(len(rows)), 0)
+	t.numFeatures = x.GetColumnNumber()
+	t.importances = normalizeImportances(b.importances)
+	return nil
+}
+
+//predict the value of the leaf of every row
+func (t *DecisionTreeRegressor) Predict(x *Matrix) (*Vector, error) {
+	if err := validateTreePredictInput(t.root, t.numFeatures, x); err != nil {
+		return nil, err
+	}
+
+	rows := x.rowSlices()
+	result := NewZeroVector(len(rows))
+	for i, row := range rows {
+		result.val[i] = t.root.apply(row).value[0]
+	}
+	return result, nil
+}
+
+//get the share of every column in the impurity decrease of the tree, summing up to 1
+func (t *DecisionTreeRegressor) FeatureImportances() *Vector {
+	return NewVector(copyFloats(t.importances))
+}
+
+//get the number of splits on the longest path from the root to a leaf
+func (t *DecisionTreeRegressor) GetDepth() int {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.depth()
+}
+
+func (t *DecisionTreeRegressor) GetNumLeaves() int {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.numLeaves()
+}
+
+//text dump of the tree, every leaf shows its predicted value
+func (t *DecisionTreeRegressor) String() string {
+	if t.root == nil {
+		return "DecisionTreeRegressor (not fitted)\n"
+	}
+
+	var sb strings.Builder
+	t.root.dump(&sb, "", func(value []float64) string {
+		return fmt.Sprintf("value: %.5f", value[0])
+	})
+	return sb.String()
+}
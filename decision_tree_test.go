@@ -0,0 +1,201 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDecisionTree(t *testing.T) {
+	_, err := NewDecisionTreeClassifier(MSECriterion, 3)
+	assert.Error(t, err)
+
+	_, err = NewDecisionTreeRegressor(GiniCriterion, 3)
+	assert.Error(t, err)
+
+	_, err = NewDecisionTreeRegressor(MSECriterion, -1)
+	assert.Error(t, err)
+
+	tree, err := NewDecisionTreeClassifier(EntropyCriterion, 0)
+	assert.NoError(t, err)
+	_, err = tree.Predict(NewConstantMatrix(1, 2, 0))
+	assert.Equal(t, ErrNotFitted, err)
+
+	tree.MinSamplesLeaf = 0
+	assert.Error(t, tree.Fit(NewConstantMatrix(2, 2, 0), NewVector([]float64{0, 1})))
+}
+
+func TestImpurityCounters(t *testing.T) {
+	y := []float64{0, 0, 1, 1}
+	gini := &classCounter{y: y, criterion: GiniCriterion, left: make([]float64, 2), right: make([]float64, 2)}
+	gini.reset(allRows(4))
+	_, r := gini.impurities()
+	assert.Equal(t, 0.5, r)
+
+	gini.move(0)
+	l, r := gini.impurities()
+	assert.Equal(t, float64(0), l)
+	assert.InDelta(t, 4.0/9, r, 1e-12)
+
+	entropy := &classCounter{y: y, criterion: EntropyCriterion, left: make([]float64, 2), right: make([]float64, 2)}
+	entropy.reset(allRows(4))
+	_, r = entropy.impurities()
+	assert.Equal(t, float64(1), r)
+
+	values := []float64{1, 2, 3, 10}
+	mse := &varianceCounter{y: values}
+	mse.reset(allRows(4))
+	mse.move(0)
+	mse.move(1)
+	l, r = mse.impurities()
+	assert.InDelta(t, 0.25, l, 1e-12)
+	assert.InDelta(t, 12.25, r, 1e-12)
+
+	mae := &maeCounter{y: values}
+	mae.reset(allRows(4))
+	mae.move(0)
+	l, r = mae.impurities()
+	assert.Equal(t, float64(0), l)
+	assert.InDelta(t, 8.0/3, r, 1e-12)
+}
+
+func TestDecisionTreeClassifier(t *testing.T) {
+	//xor can't be learned by a linear model
+	x, _ := NewMatrix([][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {0, 0}, {1, 1}})
+	y := NewVector([]float64{0, 1, 1, 0, 0, 0})
+
+	tree, _ := NewDecisionTreeClassifier(GiniCriterion, 0)
+	assert.NoError(t, tree.Fit(x, y))
+
+	result, err := tree.Predict(x)
+	assert.NoError(t, err)
+	assert.Equal(t, y.val, result.val)
+	assert.Equal(t, 2, tree.GetDepth())
+	assert.Equal(t, 4, tree.GetNumLeaves())
+
+	proba, _ := tree.PredictProba(x)
+	assert.Equal(t, []float64{1, 0}, proba.val[1].val)
+
+	importances := tree.FeatureImportances()
+	assert.InDelta(t, 1, importances.val[0]+importances.val[1], 1e-12)
+
+	dump := tree.String()
+	fmt.Print(dump)
+	assert.True(t, strings.Contains(dump, "|--- x"))
+	assert.True(t, strings.Contains(dump, "class: 1"))
+
+	//a depth limit gives a single split
+	tree.MaxDepth = 1
+	tree.Fit(x, y)
+	assert.Equal(t, 1, tree.GetDepth())
+
+	_, err = tree.Predict(NewConstantMatrix(1, 3, 0))
+	assert.Error(t, err)
+}
+
+func TestDecisionTreeAdjacentValues(t *testing.T) {
+	//the midpoint of adjacent floats rounds up and the one of huge values overflows
+	small := math.Nextafter(1, 2)
+	pairs := [][2]float64{
+		{small, math.Nextafter(small, 2)},
+		{1.7e308, math.MaxFloat64},
+	}
+
+	for _, p := range pairs {
+		threshold := midpoint(p[0], p[1])
+		assert.True(t, p[0] <= threshold && threshold < p[1])
+
+		x, _ := NewMatrix([][]float64{{p[0]}, {p[1]}, {p[0]}, {p[1]}})
+		y := NewVector([]float64{0, 1, 0, 1})
+
+		tree, _ := NewDecisionTreeClassifier(GiniCriterion, 0)
+		assert.NoError(t, tree.Fit(x, y))
+		assert.Equal(t, 1, tree.GetDepth())
+
+		result, err := tree.Predict(x)
+		assert.NoError(t, err)
+		assert.Equal(t, y.val, result.val)
+	}
+
+	//distinct values that are far enough apart still get the plain midpoint
+	assert.Equal(t, float64(2), midpoint(1, 3))
+}
+
+func TestDecisionTreeClassifierFromExData(t *testing.T) {
+	//non-linear boundary of data2.csv without NewFeatureMatrix
+	x, _ := LoadNewMatrix("data2.csv", ":", "1:2")
+	y, _ := LoadNewVector("data2.csv", ":", "3")
+
+	folds, _ := NewStratifiedKFold(5, true, 1)
+	for _, criterion := range []Criterion{GiniCriterion, EntropyCriterion} {
+		result, err := CrossValidate(func() Estimator {
+			return &DecisionTreeClassifier{Criterion: criterion, MaxDepth: 5, MinSamplesSplit: 2, MinSamplesLeaf: 3}
+		}, x, y, folds, Accuracy)
+		assert.NoError(t, err)
+		fmt.Printf("Decision tree (%v) accuracy on data2.csv: %v\n", criterion, result.Mean)
+		assert.True(t, result.Mean > 0.7)
+	}
+
+	//a fully grown tree fits the training data
+	tree, _ := NewDecisionTreeClassifier(GiniCriterion, 0)
+	tree.Fit(x, y)
+	result, _ := tree.Predict(x)
+	acc, _ := Accuracy(result, y)
+	assert.True(t, acc > 0.99)
+
+	//min samples per leaf
+	tree.MinSamplesLeaf = 10
+	tree.Fit(x, y)
+	var checkLeaves func(n *treeNode)
+	checkLeaves = func(n *treeNode) {
+		if n.left == nil {
+			assert.True(t, n.samples >= 10)
+			return
+		}
+		checkLeaves(n.left)
+		checkLeaves(n.right)
+	}
+	checkLeaves(tree.root)
+}
+
+func TestDecisionTreeRegressor(t *testing.T) {
+	x, _ := NewMatrix([][]float64{{1, 5}, {2, 5}, {3, 5}, {10, 5}, {11, 5}, {12, 5}})
+	y := NewVector([]float64{1, 1, 2, 10, 10, 40})
+
+	tree, _ := NewDecisionTreeRegressor(MSECriterion, 1)
+	assert.NoError(t, tree.Fit(x, y))
+	assert.Equal(t, 1, tree.GetDepth())
+	assert.Equal(t, 2, tree.GetNumLeaves())
+
+	query, _ := NewMatrix([][]float64{{0, 0}, {20, 0}})
+	result, err := tree.Predict(query)
+	assert.NoError(t, err)
+	//the outlier 40 pulls the split to the right
+	assert.InDeltaSlice(t, []float64{4.8, 40}, result.val, 1e-12)
+
+	//the second column never changes
+	assert.Equal(t, []float64{1, 0}, tree.FeatureImportances().val)
+
+	//mae predicts the median of a leaf instead of the mean
+	tree.Criterion = MAECriterion
+	tree.Fit(x, y)
+	result, _ = tree.Predict(query)
+	assert.Equal(t, []float64{2, 40}, result.val)
+	fmt.Print(tree)
+}
+
+func TestDecisionTreeRegressorFromExData(t *testing.T) {
+	xTrain, xTest, yTrain, yTest, _ := SequentialTrainTestSplit(loadData3(), loadData3Y(), 0.8)
+
+	tree, _ := NewDecisionTreeRegressor(MSECriterion, 3)
+	tree.MinSamplesLeaf = 5
+	assert.NoError(t, tree.Fit(xTrain, yTrain))
+
+	result, _ := tree.Predict(xTest)
+	mae, _ := MeanAbsoluteError(result, yTest)
+	fmt.Printf("Decision tree mean absolute error on data3.csv: %v\n", mae)
+	assert.True(t, mae < 5)
+}
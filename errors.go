@@ -5,11 +5,17 @@ import "errors"
 var (
 	//General error to prevent panic
 	ErrOutOfRange = errors.New("Index out of range")
+	//Error for row/col specification strings such as "1:80" that can't be parsed
+	ErrInvalidIndexSpec = errors.New("Invalid index specification")
+	//Error for views that select the same row more than once
+	ErrDuplicateIndex = errors.New("Index is selected more than once")
 
 	//Vector Errors
 	//Error for vectors calculation operators
 	//some operations need exact same dimension to work
 	ErrVectorFalseDimension = errors.New("Dimensions of both vectors don't agree")
+	//Error for operations that need at least 1 element e.g. mean or median
+	ErrEmptyVector = errors.New("Vector is empty")
 
 	//Matrix Errors
 	//Error for matrix operations that require more than 1 vector
@@ -18,4 +24,10 @@ var (
 	//Error for empty matrix
 	//some operations require it to have at least 1 row
 	ErrEmptyMatrix = errors.New("Matrix is empty")
+
+	//Model Errors
+	//Error for calling predict on a model that hasn't been trained yet
+	ErrNotFitted = errors.New("Model has not been fitted yet")
+	//Error for full batch training of an online model which only learns from batches
+	ErrNoTrainingData = errors.New("Model has no training data")
 )
@@ -0,0 +1,514 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type (
+	//BoostingLoss is the loss minimized by gradient boosting
+	BoostingLoss int
+
+	//boosting holds the shared parameters and state of both boosting models
+	//the raw prediction of a row is init + LearningRate * sigma(tree predictions)
+	boosting struct {
+		NumRounds          int
+		LearningRate       float64
+		MaxDepth           int
+		MinSamplesLeaf     int
+		Subsample          float64
+		ValidationFraction float64
+		NIterNoChange      int
+		Tol                float64
+		Seed               int64
+
+		loss        BoostingLoss
+		delta       float64
+		init        float64
+		trees       []*treeNode
+		numFeatures int
+		trainLoss   []float64
+		validLoss   []float64
+	}
+
+	//GradientBoostingRegressor fits shallow regression trees to the negative gradient of Loss
+	//every tree is trained on a Subsample share of the rows drawn without replacement
+	//if NIterNoChange is greater than 0, ValidationFraction of the rows are held out and
+	//training stops once the validation loss didn't improve by Tol for NIterNoChange rounds,
+	//the model then keeps the rounds up to the best validation loss
+	//Delta is the threshold between squared and absolute error of the huber loss
+	GradientBoostingRegressor struct {
+		boosting
+		Loss  BoostingLoss
+		Delta float64
+	}
+
+	//GradientBoostingClassifier is gradient boosting with log loss for labels 0 and 1
+	//the probability of label 1 is sigm of the raw prediction
+	GradientBoostingClassifier struct {
+		boosting
+	}
+)
+
+const (
+	SquaredLoss BoostingLoss = iota
+	AbsoluteLoss
+	HuberLoss
+	//binary log loss, only used by GradientBoostingClassifier
+	LogLossBoosting
+)
+
+func (l BoostingLoss) String() string {
+	switch l {
+	case SquaredLoss:
+		return "squared"
+	case AbsoluteLoss:
+		return "absolute"
+	case HuberLoss:
+		return "huber"
+	case LogLossBoosting:
+		return "log"
+	}
+	return fmt.Sprintf("BoostingLoss(%d)", int(l))
+}
+
+func newBoosting(numRounds int, learningRate float64, seed int64) boosting {
+	return boosting{
+		NumRounds:          numRounds,
+		LearningRate:       learningRate,
+		MaxDepth:           3,
+		MinSamplesLeaf:     1,
+		Subsample:          1,
+		ValidationFraction: 0.1,
+		Tol:                1e-4,
+		Seed:               seed,
+	}
+}
+
+//create a boosting regressor with trees of depth 3 and no early stopping
+func NewGradientBoostingRegressor(loss BoostingLoss, numRounds int, learningRate float64, seed int64) (*GradientBoostingRegressor, error) {
+	gb := &GradientBoostingRegressor{boosting: newBoosting(numRounds, learningRate, seed), Loss: loss, Delta: 1}
+	if err := gb.validateRegressor(); err != nil {
+		return nil, err
+	}
+	return gb, nil
+}
+
+//create a boosting classifier with trees of depth 3 and no early stopping
+func NewGradientBoostingClassifier(numRounds int, learningRate float64, seed int64) (*GradientBoostingClassifier, error) {
+	gb := &GradientBoostingClassifier{boosting: newBoosting(numRounds, learningRate, seed)}
+	if err := gb.validate(); err != nil {
+		return nil, err
+	}
+	return gb, nil
+}
+
+func (b *boosting) validate() error {
+	if b.NumRounds < 1 {
+		return fmt.Errorf("Number of rounds should be at least 1")
+	}
+
+	if b.LearningRate <= 0 {
+		return fmt.Errorf("Learning rate should be greater than 0")
+	}
+
+	if b.MaxDepth < 1 {
+		return fmt.Errorf("MaxDepth should be at least 1")
+	}
+
+	if b.MinSamplesLeaf < 1 {
+		return fmt.Errorf("MinSamplesLeaf should be at least 1")
+	}
+
+	if b.Subsample <= 0 || b.Subsample > 1 {
+		return fmt.Errorf("Subsample should be in range (0, 1]")
+	}
+
+	if b.NIterNoChange < 0 {
+		return fmt.Errorf("NIterNoChange should not be negative")
+	}
+
+	if b.NIterNoChange > 0 && (b.ValidationFraction <= 0 || b.ValidationFraction >= 1) {
+		return fmt.Errorf("ValidationFraction should be between 0 and 1")
+	}
+
+	return nil
+}
+
+func (gb *GradientBoostingRegressor) validateRegressor() error {
+	if gb.Loss < SquaredLoss || gb.Loss > HuberLoss {
+		return fmt.Errorf("Loss %v can't be used for regression", gb.Loss)
+	}
+
+	if gb.Loss == HuberLoss && gb.Delta <= 0 {
+		return fmt.Errorf("Huber delta should be greater than 0")
+	}
+
+	return gb.validate()
+}
+
+///////////////////////////
+////////LOSS//////////////
+//////////////////////////
+
+//mean loss of the raw predictions f
+func (b *boosting) lossValue(y, f []float64) float64 {
+	var result float64
+	for i := range y {
+		d := y[i] - f[i]
+		switch b.loss {
+		case SquaredLoss:
+			result += d * d
+		case AbsoluteLoss:
+			result += math.Abs(d)
+		case HuberLoss:
+			if math.Abs(d) <= b.delta {
+				result += d * d / 2
+			} else {
+				result += b.delta * (math.Abs(d) - b.delta/2)
+			}
+		case LogLossBoosting:
+			p := math.Min(math.Max(sigm(f[i]), 1e-15), 1-1e-15)
+			result -= y[i]*math.Log(p) + (1-y[i])*math.Log(1-p)
+		}
+	}
+	return result / float64(len(y))
+}
+
+//negative gradient of the loss for row i
+func (b *boosting) negativeGradient(y, f float64) float64 {
+	d := y - f
+	switch b.loss {
+	case AbsoluteLoss:
+		return sign(d)
+	case HuberLoss:
+		if math.Abs(d) <= b.delta {
+			return d
+		}
+		return b.delta * sign(d)
+	case LogLossBoosting:
+		return y - sigm(f)
+	}
+	return d
+}
+
+func sign(f float64) float64 {
+	if f > 0 {
+		return 1
+	}
+	if f < 0 {
+		return -1
+	}
+	return 0
+}
+
+//the constant raw prediction minimizing the loss
+func (b *boosting) initValue(y []float64) float64 {
+	switch b.loss {
+	case AbsoluteLoss, HuberLoss:
+		return median(y)
+	case LogLossBoosting:
+		p := math.Min(math.Max(mean(NewVector(y)), 1e-15), 1-1e-15)
+		return math.Log(p / (1 - p))
+	}
+	return mean(NewVector(y))
+}
+
+//optimal value of a leaf with the rows idx, the tree itself is fit to the negative gradient
+//which is only the right leaf value for the squared loss
+func (b *boosting) leafValue(idx []int, y, f []float64) float64 {
+	residuals := make([]float64, len(idx))
+	for k, i := range idx {
+		residuals[k] = y[i] - f[i]
+	}
+
+	switch b.loss {
+	case AbsoluteLoss:
+		return median(residuals)
+	case HuberLoss:
+		//one step of friedman's huber update starting at the median
+		med := median(residuals)
+		var result float64
+		for _, r := range residuals {
+			d := r - med
+			result += sign(d) * math.Min(b.delta, math.Abs(d))
+		}
+		return med + result/float64(len(residuals))
+	case LogLossBoosting:
+		//newton step sigma(y - p) / sigma(p * (1 - p))
+		var num, den float64
+		for _, i := range idx {
+			p := sigm(f[i])
+			num += y[i] - p
+			den += p * (1 - p)
+		}
+		if den < 1e-150 {
+			return 0
+		}
+		return num / den
+	}
+	return mean(NewVector(residuals))
+}
+
+///////////////////////////
+////////TRAINING//////////
+//////////////////////////
+
+//train the rounds on x and y, with early stopping part of the rows are held out
+func (b *boosting) fit(x *Matrix, y *Vector, stratify bool) error {
+	if err := validateTreeInput(x, y); err != nil {
+		return err
+	}
+
+	rnd := rand.New(rand.NewSource(b.Seed))
+
+	xTrain, yTrain := x, y
+	var xValid *Matrix
+	var yValid *Vector
+	if b.NIterNoChange > 0 {
+		var err error
+		xTrain, xValid, yTrain, yValid, err = trainTestSplit(x, y, 1-b.ValidationFraction, rnd, stratify)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, yv := xTrain.rowSlices(), yTrain.val
+	b.numFeatures = x.GetColumnNumber()
+	b.init = b.initValue(yv)
+	b.trees, b.trainLoss, b.validLoss = nil, nil, nil
+
+	f := make([]float64, len(rows))
+	for i := range f {
+		f[i] = b.init
+	}
+
+	var validRows [][]float64
+	var fValid []float64
+	if xValid != nil {
+		validRows = xValid.rowSlices()
+		fValid = make([]float64, len(validRows))
+		for i := range fValid {
+			fValid[i] = b.init
+		}
+	}
+
+	p := treeParams{MSECriterion, b.MaxDepth, 2, b.MinSamplesLeaf, 0}
+	gradient := make([]float64, len(rows))
+	sampleSize := maxInt(1, int(math.Round(b.Subsample*float64(len(rows)))))
+
+	best, bestRound, noChange := math.Inf(1), 0, 0
+	for round := 1; round <= b.NumRounds; round++ {
+		for i := range rows {
+			gradient[i] = b.negativeGradient(yv[i], f[i])
+		}
+
+		idx := allRows(len(rows))
+		if sampleSize < len(rows) {
+			idx = rnd.Perm(len(rows))[:sampleSize]
+		}
+
+		tree := newTreeBuilder(p, rows, gradient, 0, rnd).build(idx, 0)
+
+		//replace the leaf values with the optimal ones for the loss
+		leaves := map[*treeNode][]int{}
+		for _, i := range idx {
+			leaf := tree.apply(rows[i])
+			leaves[leaf] = append(leaves[leaf], i)
+		}
+		for leaf, leafIdx := range leaves {
+			leaf.value = []float64{b.leafValue(leafIdx, yv, f)}
+		}
+
+		b.trees = append(b.trees, tree)
+		for i, row := range rows {
+			f[i] += b.LearningRate * tree.apply(row).value[0]
+		}
+		b.trainLoss = append(b.trainLoss, b.lossValue(yv, f))
+
+		if validRows == nil {
+			continue
+		}
+
+		for i, row := range validRows {
+			fValid[i] += b.LearningRate * tree.apply(row).value[0]
+		}
+		loss := b.lossValue(yValid.val, fValid)
+		b.validLoss = append(b.validLoss, loss)
+
+		if loss < best-b.Tol {
+			best, bestRound, noChange = loss, round, 0
+		} else if noChange++; noChange >= b.NIterNoChange {
+			break
+		}
+	}
+
+	if validRows != nil && bestRound > 0 {
+		b.trees = b.trees[:bestRound]
+	}
+	return nil
+}
+
+//x should have been fit and have as many columns as the training data
+func (b *boosting) validatePredictInput(x *Matrix) error {
+	if b.trees == nil {
+		return ErrNotFitted
+	}
+	return validateTreePredictInput(b.trees[0], b.numFeatures, x)
+}
+
+//raw predictions after every round, result[r] is the prediction with r+1 trees
+func (b *boosting) stagedRaw(x *Matrix) ([][]float64, error) {
+	if err := b.validatePredictInput(x); err != nil {
+		return nil, err
+	}
+
+	rows := x.rowSlices()
+	f := make([]float64, len(rows))
+	for i := range f {
+		f[i] = b.init
+	}
+
+	result := make([][]float64, len(b.trees))
+	for r, tree := range b.trees {
+		for i, row := range rows {
+			f[i] += b.LearningRate * tree.apply(row).value[0]
+		}
+		result[r] = copyFloats(f)
+	}
+	return result, nil
+}
+
+func (b *boosting) raw(x *Matrix) ([]float64, error) {
+	staged, err := b.stagedRaw(x)
+	if err != nil {
+		return nil, err
+	}
+	return staged[len(staged)-1], nil
+}
+
+//get the number of trees of the model
+func (b *boosting) GetNumRounds() int { return len(b.trees) }
+
+//get the mean loss on the training rows after every round
+func (b *boosting) TrainLoss() *Vector { return NewVector(copyFloats(b.trainLoss)) }
+
+//get the mean loss on the held out rows after every round, empty without early stopping
+func (b *boosting) ValidationLoss() *Vector { return NewVector(copyFloats(b.validLoss)) }
+
+///////////////////////////
+////////REGRESSOR/////////
+//////////////////////////
+
+func (gb *GradientBoostingRegressor) Fit(x *Matrix, y *Vector) error {
+	if err := gb.validateRegressor(); err != nil {
+		return err
+	}
+
+	gb.loss, gb.delta = gb.Loss, gb.Delta
+	return gb.fit(x, y, false)
+}
+
+func (gb *GradientBoostingRegressor) Predict(x *Matrix) (*Vector, error) {
+	f, err := gb.raw(x)
+	if err != nil {
+		return nil, err
+	}
+	return NewVector(f), nil
+}
+
+//predict after every round to choose the number of rounds
+//result[r] is the prediction with the first r+1 trees
+func (gb *GradientBoostingRegressor) StagedPredict(x *Matrix) ([]*Vector, error) {
+	staged, err := gb.stagedRaw(x)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Vector, len(staged))
+	for r, f := range staged {
+		result[r] = NewVector(f)
+	}
+	return result, nil
+}
+
+///////////////////////////
+////////CLASSIFIER////////
+//////////////////////////
+
+//labels should be 0 or 1
+func (gb *GradientBoostingClassifier) Fit(x *Matrix, y *Vector) error {
+	if err := gb.validate(); err != nil {
+		return err
+	}
+
+	if err := validateBinaryLabels(y); err != nil {
+		return err
+	}
+
+	gb.loss = LogLossBoosting
+	return gb.fit(x, y, true)
+}
+
+func probaOf(f []float64) *Vector {
+	result := NewZeroVector(len(f))
+	for i, v := range f {
+		result.val[i] = sigm(v)
+	}
+	return result
+}
+
+func labelsOf(proba *Vector) *Vector {
+	return proba.Map(func(p float64) float64 {
+		if p < 0.5 {
+			return 0
+		}
+		return 1
+	})
+}
+
+//calculate the probability of label 1 for every row
+func (gb *GradientBoostingClassifier) PredictProba(x *Matrix) (*Vector, error) {
+	f, err := gb.raw(x)
+	if err != nil {
+		return nil, err
+	}
+	return probaOf(f), nil
+}
+
+//predict 1 if the probability is at least 0.5 and 0 otherwise
+func (gb *GradientBoostingClassifier) Predict(x *Matrix) (*Vector, error) {
+	proba, err := gb.PredictProba(x)
+	if err != nil {
+		return nil, err
+	}
+	return labelsOf(proba), nil
+}
+
+//calculate the probability of label 1 after every round
+func (gb *GradientBoostingClassifier) StagedPredictProba(x *Matrix) ([]*Vector, error) {
+	staged, err := gb.stagedRaw(x)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Vector, len(staged))
+	for r, f := range staged {
+		result[r] = probaOf(f)
+	}
+	return result, nil
+}
+
+//predict the labels after every round to choose the number of rounds
+func (gb *GradientBoostingClassifier) StagedPredict(x *Matrix) ([]*Vector, error) {
+	staged, err := gb.StagedPredictProba(x)
+	if err != nil {
+		return nil, err
+	}
+
+	for r, proba := range staged {
+		staged[r] = labelsOf(proba)
+	}
+	return staged, nil
+}
@@ -0,0 +1,140 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGradientBoosting(t *testing.T) {
+	_, err := NewGradientBoostingRegressor(LogLossBoosting, 10, 0.1, 1)
+	assert.Error(t, err)
+
+	_, err = NewGradientBoostingRegressor(SquaredLoss, 0, 0.1, 1)
+	assert.Error(t, err)
+
+	_, err = NewGradientBoostingClassifier(10, 0, 1)
+	assert.Error(t, err)
+
+	gb, _ := NewGradientBoostingRegressor(HuberLoss, 10, 0.1, 1)
+	gb.Delta = 0
+	assert.Error(t, gb.Fit(NewConstantMatrix(3, 1, 0), NewVector([]float64{1, 2, 3})))
+
+	gb.Delta, gb.Subsample = 1, 1.5
+	assert.Error(t, gb.Fit(NewConstantMatrix(3, 1, 0), NewVector([]float64{1, 2, 3})))
+
+	_, err = gb.Predict(NewConstantMatrix(1, 1, 0))
+	assert.Equal(t, ErrNotFitted, err)
+}
+
+func TestBoostingLoss(t *testing.T) {
+	b := &boosting{loss: HuberLoss, delta: 1}
+	y, f := []float64{0, 0}, []float64{0.5, 3}
+	//0.5^2 / 2 and 1 * (3 - 0.5)
+	assert.InDelta(t, (0.125+2.5)/2, b.lossValue(y, f), 1e-12)
+	assert.Equal(t, float64(-1), b.negativeGradient(0, 3))
+	assert.Equal(t, -0.5, b.negativeGradient(0, 0.5))
+
+	b.loss = LogLossBoosting
+	assert.InDelta(t, math.Log(3), b.initValue([]float64{1, 1, 1, 0}), 1e-12)
+	assert.InDelta(t, 0.5, b.negativeGradient(1, 0), 1e-12)
+
+	b.loss = AbsoluteLoss
+	assert.Equal(t, float64(2), b.initValue([]float64{1, 2, 100}))
+	assert.Equal(t, float64(2), b.leafValue([]int{0, 1, 2}, []float64{1, 2, 100}, []float64{0, 0, 0}))
+}
+
+func TestGradientBoostingRegressor(t *testing.T) {
+	xTrain, xTest, yTrain, yTest, _ := SequentialTrainTestSplit(loadData3(), loadData3Y(), 0.8)
+
+	for _, loss := range []BoostingLoss{SquaredLoss, AbsoluteLoss, HuberLoss} {
+		gb, _ := NewGradientBoostingRegressor(loss, 100, 0.1, 1)
+		gb.Subsample = 0.8
+		assert.NoError(t, gb.Fit(xTrain, yTrain))
+		assert.Equal(t, 100, gb.GetNumRounds())
+
+		result, err := gb.Predict(xTest)
+		assert.NoError(t, err)
+		mae, _ := MeanAbsoluteError(result, yTest)
+		fmt.Printf("Gradient boosting (%v loss) mean absolute error on data3.csv: %v\n", loss, mae)
+		assert.True(t, mae < 5)
+	}
+
+	gb, _ := NewGradientBoostingRegressor(SquaredLoss, 50, 0.1, 1)
+	gb.Fit(xTrain, yTrain)
+	//the training loss never increases for the squared loss without subsampling
+	loss := gb.TrainLoss()
+	assert.Equal(t, 50, loss.GetLength())
+	for i := 1; i < loss.GetLength(); i++ {
+		assert.True(t, loss.val[i] <= loss.val[i-1]+1e-12)
+	}
+
+	//the last staged prediction is the prediction
+	staged, err := gb.StagedPredict(xTest)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, len(staged))
+	result, _ := gb.Predict(xTest)
+	assert.Equal(t, result, staged[49])
+
+	_, err = gb.StagedPredict(NewConstantMatrix(1, 2, 0))
+	assert.Error(t, err)
+}
+
+func TestGradientBoostingEarlyStopping(t *testing.T) {
+	x, y := loadData3(), loadData3Y()
+
+	gb, _ := NewGradientBoostingRegressor(SquaredLoss, 1000, 0.3, 1)
+	gb.NIterNoChange = 5
+	gb.ValidationFraction = 0.2
+	assert.NoError(t, gb.Fit(x, y))
+
+	valid := gb.ValidationLoss()
+	fmt.Printf("Gradient boosting stopped after %v rounds, kept %v\n", valid.GetLength(), gb.GetNumRounds())
+	assert.True(t, valid.GetLength() < 1000)
+	assert.Equal(t, valid.GetLength()-5, gb.GetNumRounds())
+
+	//the kept rounds have the lowest validation loss
+	best := valid.val[gb.GetNumRounds()-1]
+	for _, l := range valid.val {
+		assert.True(t, best <= l+gb.Tol)
+	}
+
+	gb.ValidationFraction = 0
+	assert.Error(t, gb.Fit(x, y))
+}
+
+func TestGradientBoostingClassifier(t *testing.T) {
+	x, _ := LoadNewMatrix("data2.csv", ":", "1:2")
+	y, _ := LoadNewVector("data2.csv", ":", "3")
+
+	gb, _ := NewGradientBoostingClassifier(100, 0.1, 1)
+	assert.Error(t, gb.Fit(x, NewConstantVector(y.GetLength(), 2)))
+
+	folds, _ := NewStratifiedKFold(5, true, 1)
+	result, err := CrossValidate(func() Estimator {
+		gb, _ := NewGradientBoostingClassifier(50, 0.1, 1)
+		gb.MaxDepth = 2
+		return gb
+	}, x, y, folds, Accuracy)
+	assert.NoError(t, err)
+	fmt.Printf("Gradient boosting accuracy on data2.csv: %v\n", result.Mean)
+	assert.True(t, result.Mean > 0.7)
+
+	assert.NoError(t, gb.Fit(x, y))
+	proba, err := gb.PredictProba(x)
+	assert.NoError(t, err)
+	auc, _ := ROCAUCScore(proba, y)
+	assert.True(t, auc > 0.9)
+
+	//log loss on the training rows goes down with every round
+	staged, _ := gb.StagedPredictProba(x)
+	first, _ := LogLoss(staged[0], y)
+	last, _ := LogLoss(staged[len(staged)-1], y)
+	assert.True(t, last < first)
+
+	labels, _ := gb.StagedPredict(x)
+	predicted, _ := gb.Predict(x)
+	assert.Equal(t, predicted, labels[len(labels)-1])
+}
@@ -0,0 +1,125 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+type (
+	//GradientModel is a model trained on the gradient of its cost function
+	//e.g. LinReg and LReg, CalculateGrad should be the derivative of CostFunc by theta
+	GradientModel interface {
+		CostFunc() float64
+		CalculateGrad() *Vector
+		GetTheta() *Vector
+		SetTheta(theta *Vector) error
+	}
+
+	//GradientCheck compares the analytic gradient of a model with finite differences
+	//Errors contains |analytic - numeric| / max(|analytic|, |numeric|) of every theta
+	//RelativeError is ||analytic - numeric|| / (||analytic|| + ||numeric||) over all of them
+	GradientCheck struct {
+		Analytic      *Vector
+		Numeric       *Vector
+		Errors        *Vector
+		RelativeError float64
+	}
+)
+
+//the step used by CheckGradient if eps is 0
+const defaultGradientEps = 1e-4
+
+//calculate the derivative of CostFunc by every thetaj with central differences
+//(J(thetaj + eps) - J(thetaj - eps)) / 2eps and compare it to CalculateGrad
+//theta of the model is the same afterwards, eps 0 uses 1e-4
+func CheckGradient(model GradientModel, eps float64) (*GradientCheck, error) {
+	if eps < 0 {
+		return nil, fmt.Errorf("Eps should not be negative")
+	}
+	if eps == 0 {
+		eps = defaultGradientEps
+	}
+
+	theta := model.GetTheta()
+	analytic := model.CalculateGrad()
+	if analytic == nil {
+		return nil, ErrNoTrainingData
+	}
+
+	if analytic.GetLength() != theta.GetLength() {
+		return nil, fmt.Errorf("Gradient dimension(%d) does not agree with theta(%d)",
+			analytic.GetLength(), theta.GetLength())
+	}
+
+	//cost with thetaj set to val, theta is restored if it can't be set
+	perturbed := NewVector(copyFloats(theta.val))
+	costAt := func(j int, val float64) (float64, error) {
+		perturbed.val[j] = val
+		defer func() { perturbed.val[j] = theta.val[j] }()
+
+		if err := model.SetTheta(perturbed); err != nil {
+			model.SetTheta(theta)
+			return 0, err
+		}
+		return model.CostFunc(), nil
+	}
+
+	numeric := NewZeroVector(theta.GetLength())
+	for j, val := range theta.val {
+		plus, err := costAt(j, val+eps)
+		if err != nil {
+			return nil, err
+		}
+
+		minus, err := costAt(j, val-eps)
+		if err != nil {
+			return nil, err
+		}
+
+		numeric.val[j] = (plus - minus) / (2 * eps)
+	}
+
+	if err := model.SetTheta(theta); err != nil {
+		return nil, err
+	}
+
+	errs := NewZeroVector(theta.GetLength())
+	var diff, normA, normN float64
+	for j, a := range analytic.val {
+		n := numeric.val[j]
+		errs.val[j] = safeDivide(math.Abs(a-n), math.Max(math.Abs(a), math.Abs(n)))
+		diff += (a - n) * (a - n)
+		normA += a * a
+		normN += n * n
+	}
+
+	return &GradientCheck{
+		Analytic:      analytic,
+		Numeric:       numeric,
+		Errors:        errs,
+		RelativeError: safeDivide(math.Sqrt(diff), math.Sqrt(normA)+math.Sqrt(normN))}, nil
+}
+
+//get the greatest error of a single theta and its 1-based index
+func (c *GradientCheck) MaxError() (float64, int) {
+	index := argmax(c.Errors.val)
+	return c.Errors.val[index], index + 1
+}
+
+//the gradient is correct if every error is lesser than tol, e.g. 1e-6
+func (c *GradientCheck) Passed(tol float64) bool {
+	worst, _ := c.MaxError()
+	return worst < tol
+}
+
+//print the analytic and numeric derivative of every theta
+func (c *GradientCheck) String() string {
+	sprint := fmt.Sprintf("%6s %15s %15s %12s\n", "Theta", "Analytic", "Numeric", "Error")
+	for j, a := range c.Analytic.val {
+		sprint += fmt.Sprintf("%6d %15.8f %15.8f %12.3e\n", j+1, a, c.Numeric.val[j], c.Errors.val[j])
+	}
+
+	worst, index := c.MaxError()
+	sprint += fmt.Sprintf("Max error: %.3e (theta %d)\nRelative error: %.3e\n", worst, index, c.RelativeError)
+	return sprint
+}
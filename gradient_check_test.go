@@ -0,0 +1,91 @@
+package ml
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//linear regression whose gradient forgets the 1/m factor
+type wrongGradient struct {
+	*LinReg
+}
+
+func (w wrongGradient) CalculateGrad() *Vector {
+	grad := w.LinReg.CalculateGrad()
+	grad.MultiplyVariable(float64(w.y.GetLength()))
+	return grad
+}
+
+//linear regression which only accepts theta1 >= -1
+type boundedTheta struct {
+	*LinReg
+}
+
+func (b boundedTheta) SetTheta(theta *Vector) error {
+	if theta.val[0] < -1 {
+		return fmt.Errorf("Theta1 should be at least -1")
+	}
+	return b.LinReg.SetTheta(theta)
+}
+
+func TestCheckGradientLinearRegression(t *testing.T) {
+	x, y := loadData3(), loadData3Y()
+	lr, _ := NewLinearRegression(x, y, NewVector([]float64{-1, 0.5}), 0.01)
+	lr.AddRegularizationFactor(1)
+
+	check, err := CheckGradient(lr, 0)
+	assert.NoError(t, err)
+	fmt.Printf("Gradient check of linear regression:\n%s", check)
+	assert.True(t, check.Passed(1e-6))
+	assert.True(t, check.RelativeError < 1e-6)
+
+	//theta is restored
+	assert.Equal(t, []float64{-1, 0.5}, lr.GetTheta().val)
+
+	check, err = CheckGradient(wrongGradient{lr}, 0)
+	assert.NoError(t, err)
+	assert.False(t, check.Passed(1e-6))
+
+	_, err = CheckGradient(lr, -1)
+	assert.Error(t, err)
+
+	//theta1 - eps can't be set, the error is returned and theta restored
+	_, err = CheckGradient(boundedTheta{lr}, 0)
+	assert.Error(t, err)
+	assert.Equal(t, []float64{-1, 0.5}, lr.GetTheta().val)
+}
+
+func TestCheckGradientLogisticRegression(t *testing.T) {
+	x, y := loadScaledData1()
+	lreg, _ := NewLogisticRegression(x, y, NewVector([]float64{-2, 1, 3}), 0.1)
+	lreg.AddRegularizationFactor(0.5)
+
+	check, err := CheckGradient(lreg, 1e-5)
+	assert.NoError(t, err)
+	fmt.Printf("Gradient check of logistic regression:\n%s", check)
+	assert.True(t, check.Passed(1e-6))
+
+	worst, index := check.MaxError()
+	assert.Equal(t, check.Errors.val[index-1], worst)
+
+	//sparse input uses its own gradient
+	xs, _ := NewSparseMatrixFromMatrix(NewConstantMatrix(4, 2, 0.5))
+	slr, _ := NewSparseLogisticRegression(xs, NewVector([]float64{0, 1, 1, 0}), NewVector([]float64{0.1, 0.2, 0.3}), 0.1)
+	slr.AddRegularizationFactor(1)
+	check, _ = CheckGradient(slr, 0)
+	assert.True(t, check.Passed(1e-6))
+}
+
+func TestSetTheta(t *testing.T) {
+	lr, _ := NewLinearRegression(loadData3(), loadData3Y(), NewZeroVector(2), 0.01)
+	theta := NewVector([]float64{1, 2})
+	assert.NoError(t, lr.SetTheta(theta))
+	theta.val[0] = 5
+	assert.Equal(t, []float64{1, 2}, lr.GetTheta().val)
+	assert.Equal(t, ErrVectorFalseDimension, lr.SetTheta(NewZeroVector(3)))
+
+	lreg, _ := NewLogisticRegression(NewConstantMatrix(2, 1, 1), NewVector([]float64{0, 1}), NewZeroVector(2), 0.01)
+	assert.Error(t, lreg.SetTheta(NewZeroVector(1)))
+}
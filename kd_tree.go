@@ -0,0 +1,287 @@
+package ml
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+)
+
+type (
+	//DistanceMetric selects the distance function of the neighbors models
+	DistanceMetric int
+
+	//neighbor is a training row found by a query, index is 1-based
+	neighbor struct {
+		index int
+		dist  float64
+	}
+
+	//neighborHeap is a max heap so the farthest of the k nearest rows can be replaced
+	neighborHeap []neighbor
+
+	//kdNode is either a leaf holding row indices or splits its rows
+	//at split along dimension dim into left (lesser) and right
+	kdNode struct {
+		idx         []int
+		dim         int
+		split       float64
+		left, right *kdNode
+	}
+
+	//neighborIndex stores the rows of a matrix and finds the nearest rows of a query
+	//a kd-tree is used for the minkowski family, cosine distance is always brute force
+	neighborIndex struct {
+		rows   [][]float64
+		metric DistanceMetric
+		p      float64
+		tree   *kdNode
+	}
+)
+
+const (
+	EuclideanMetric DistanceMetric = iota
+	ManhattanMetric
+	MinkowskiMetric
+	CosineMetric
+)
+
+//leaves of the kd-tree hold at most this many rows
+const kdLeafSize = 16
+
+func (m DistanceMetric) String() string {
+	switch m {
+	case EuclideanMetric:
+		return "euclidean"
+	case ManhattanMetric:
+		return "manhattan"
+	case MinkowskiMetric:
+		return "minkowski"
+	case CosineMetric:
+		return "cosine"
+	}
+	return fmt.Sprintf("DistanceMetric(%d)", int(m))
+}
+
+//p is only used by the minkowski metric and should be at least 1
+func validateMetric(metric DistanceMetric, p float64) error {
+	switch metric {
+	case EuclideanMetric, ManhattanMetric, CosineMetric:
+		return nil
+	case MinkowskiMetric:
+		if p < 1 {
+			return fmt.Errorf("Minkowski distance needs p >= 1")
+		}
+		return nil
+	}
+	return fmt.Errorf("Unknown distance metric %v", metric)
+}
+
+//distance between two rows of the same length
+//works on the plain slices since it runs for every row of every query
+func (ni *neighborIndex) distance(a, b []float64) float64 {
+	var result float64
+	switch ni.metric {
+	case EuclideanMetric:
+		for i := range a {
+			d := a[i] - b[i]
+			result += d * d
+		}
+		return math.Sqrt(result)
+	case ManhattanMetric:
+		for i := range a {
+			result += math.Abs(a[i] - b[i])
+		}
+		return result
+	case MinkowskiMetric:
+		for i := range a {
+			result += math.Pow(math.Abs(a[i]-b[i]), ni.p)
+		}
+		return math.Pow(result, 1/ni.p)
+	}
+
+	//cosine distance, a row with norm 0 has the greatest distance to everything
+	var normA, normB float64
+	for i := range a {
+		result += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 2
+	}
+	return 1 - result/math.Sqrt(normA*normB)
+}
+
+func newNeighborIndex(x *Matrix, metric DistanceMetric, p float64, bruteForce bool) (*neighborIndex, error) {
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := validateMetric(metric, p); err != nil {
+		return nil, err
+	}
+
+	//copy the rows so later changes of x don't affect the index
+	rows := x.rowSlices()
+	for i, row := range rows {
+		rows[i] = copyFloats(row)
+	}
+
+	ni := &neighborIndex{rows: rows, metric: metric, p: p}
+	if !bruteForce && metric != CosineMetric {
+		idx := make([]int, len(rows))
+		for i := range idx {
+			idx[i] = i + 1
+		}
+		ni.tree = ni.build(idx)
+	}
+
+	return ni, nil
+}
+
+func (ni *neighborIndex) row(index int) []float64 { return ni.rows[index-1] }
+
+//split along the dimension with the greatest spread at its median
+func (ni *neighborIndex) build(idx []int) *kdNode {
+	if len(idx) <= kdLeafSize {
+		return &kdNode{idx: idx}
+	}
+
+	dim, spread := 0, 0.0
+	for d := range ni.row(idx[0]) {
+		low, high := math.Inf(1), math.Inf(-1)
+		for _, i := range idx {
+			low = math.Min(low, ni.row(i)[d])
+			high = math.Max(high, ni.row(i)[d])
+		}
+		if high-low > spread {
+			dim, spread = d, high-low
+		}
+	}
+
+	//all rows are the same point
+	if spread == 0 {
+		return &kdNode{idx: idx}
+	}
+
+	sort.Slice(idx, func(a, b int) bool { return ni.row(idx[a])[dim] < ni.row(idx[b])[dim] })
+	mid := len(idx) / 2
+	return &kdNode{
+		dim:   dim,
+		split: ni.row(idx[mid])[dim],
+		left:  ni.build(idx[:mid]),
+		right: ni.build(idx[mid:]),
+	}
+}
+
+//find the k nearest rows of q ordered by distance
+//rows with the same distance are ordered by their index
+func (ni *neighborIndex) query(q []float64, k int) []neighbor {
+	if k > len(ni.rows) {
+		k = len(ni.rows)
+	}
+
+	h := make(neighborHeap, 0, k)
+	if ni.tree == nil {
+		for i := range ni.rows {
+			h.offer(neighbor{index: i + 1, dist: ni.distance(q, ni.rows[i])}, k)
+		}
+	} else {
+		ni.search(ni.tree, q, k, &h)
+	}
+
+	result := []neighbor(h)
+	sort.Slice(result, func(a, b int) bool { return result[a].less(result[b]) })
+	return result
+}
+
+func (ni *neighborIndex) search(node *kdNode, q []float64, k int, h *neighborHeap) {
+	if node.left == nil {
+		for _, i := range node.idx {
+			h.offer(neighbor{index: i, dist: ni.distance(q, ni.row(i))}, k)
+		}
+		return
+	}
+
+	near, far := node.left, node.right
+	diff := q[node.dim] - node.split
+	if diff >= 0 {
+		near, far = far, near
+	}
+
+	ni.search(near, q, k, h)
+	//a single coordinate difference is a lower bound of every minkowski distance
+	//so the other side can be skipped if it is farther than the current k-th row
+	if h.Len() < k || math.Abs(diff) <= (*h)[0].dist {
+		ni.search(far, q, k, h)
+	}
+}
+
+//find every row within radius of q ordered by distance
+func (ni *neighborIndex) queryRadius(q []float64, radius float64) []neighbor {
+	var result []neighbor
+	if ni.tree == nil {
+		for i := range ni.rows {
+			if d := ni.distance(q, ni.rows[i]); d <= radius {
+				result = append(result, neighbor{index: i + 1, dist: d})
+			}
+		}
+	} else {
+		result = ni.searchRadius(ni.tree, q, radius, result)
+	}
+
+	sort.Slice(result, func(a, b int) bool { return result[a].less(result[b]) })
+	return result
+}
+
+func (ni *neighborIndex) searchRadius(node *kdNode, q []float64, radius float64, result []neighbor) []neighbor {
+	if node.left == nil {
+		for _, i := range node.idx {
+			if d := ni.distance(q, ni.row(i)); d <= radius {
+				result = append(result, neighbor{index: i, dist: d})
+			}
+		}
+		return result
+	}
+
+	diff := q[node.dim] - node.split
+	if diff < 0 || math.Abs(diff) <= radius {
+		result = ni.searchRadius(node.left, q, radius, result)
+	}
+	if diff >= 0 || math.Abs(diff) <= radius {
+		result = ni.searchRadius(node.right, q, radius, result)
+	}
+	return result
+}
+
+func (n neighbor) less(n2 neighbor) bool {
+	if n.dist != n2.dist {
+		return n.dist < n2.dist
+	}
+	return n.index < n2.index
+}
+
+func (h neighborHeap) Len() int            { return len(h) }
+func (h neighborHeap) Less(i, j int) bool  { return h[j].less(h[i]) }
+func (h neighborHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *neighborHeap) Push(x interface{}) { *h = append(*h, x.(neighbor)) }
+func (h *neighborHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+//keep n if it is one of the k nearest so far
+func (h *neighborHeap) offer(n neighbor, k int) {
+	if h.Len() < k {
+		heap.Push(h, n)
+		return
+	}
+
+	if n.less((*h)[0]) {
+		(*h)[0] = n
+		heap.Fix(h, 0)
+	}
+}
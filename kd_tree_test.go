@@ -0,0 +1,86 @@
+package ml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeighborIndexDistance(t *testing.T) {
+	a, b := []float64{1, 2}, []float64{4, 6}
+	v1, v2 := NewVector(a), NewVector(b)
+
+	euclidean, _ := EuclideanDistance(v1, v2)
+	manhattan, _ := ManhattanDistance(v1, v2)
+	minkowski, _ := MinkowskiDistance(v1, v2, 3)
+	cosine, _ := CosineDistance(v1, v2)
+
+	for metric, expected := range map[DistanceMetric]float64{
+		EuclideanMetric: euclidean,
+		ManhattanMetric: manhattan,
+		MinkowskiMetric: minkowski,
+		CosineMetric:    cosine,
+	} {
+		ni := &neighborIndex{metric: metric, p: 3}
+		assert.InDelta(t, expected, ni.distance(a, b), 1e-12, metric.String())
+	}
+
+	assert.Error(t, validateMetric(MinkowskiMetric, 0.5))
+	assert.Error(t, validateMetric(DistanceMetric(10), 2))
+}
+
+func TestNeighborIndexQuery(t *testing.T) {
+	x := newRandomMatrix(500, 3, 1)
+	//duplicates and ties should be handled the same by both indices
+	for i := 1; i <= 50; i++ {
+		x.val[i+50] = x.val[i].Clone()
+	}
+	queries := newRandomMatrix(30, 3, 2)
+	queries.val[1] = x.val[1].Clone()
+
+	for _, metric := range []DistanceMetric{EuclideanMetric, ManhattanMetric, MinkowskiMetric} {
+		tree, err := newNeighborIndex(x, metric, 3, false)
+		assert.NoError(t, err)
+		assert.NotNil(t, tree.tree)
+
+		brute, _ := newNeighborIndex(x, metric, 3, true)
+		assert.Nil(t, brute.tree)
+
+		for i := 1; i <= queries.GetRowNumber(); i++ {
+			q := queries.val[i].val
+			assert.Equal(t, brute.query(q, 7), tree.query(q, 7))
+			assert.Equal(t, brute.queryRadius(q, 0.4), tree.queryRadius(q, 0.4))
+		}
+	}
+
+	//the query row itself has distance 0 and its duplicate comes next
+	tree, _ := newNeighborIndex(x, EuclideanMetric, 2, false)
+	result := tree.query(x.val[1].val, 2)
+	assert.Equal(t, []neighbor{{1, 0}, {51, 0}}, result)
+
+	//k greater than the number of rows returns all rows
+	assert.Equal(t, 500, len(tree.query(x.val[1].val, 1000)))
+
+	//cosine is always brute force
+	cosine, _ := newNeighborIndex(x, CosineMetric, 2, false)
+	assert.Nil(t, cosine.tree)
+
+	_, err := newNeighborIndex(&Matrix{}, EuclideanMetric, 2, false)
+	assert.Error(t, err)
+}
+
+func benchmarkNeighborIndex(b *testing.B, bruteForce bool) {
+	x := newRandomMatrix(20000, 3, 1)
+	queries := newRandomMatrix(100, 3, 2)
+	ni, _ := newNeighborIndex(x, EuclideanMetric, 2, bruteForce)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 1; j <= queries.GetRowNumber(); j++ {
+			ni.query(queries.val[j].val, 5)
+		}
+	}
+}
+
+func BenchmarkNeighborIndexKDTree(b *testing.B)     { benchmarkNeighborIndex(b, false) }
+func BenchmarkNeighborIndexBruteForce(b *testing.B) { benchmarkNeighborIndex(b, true) }
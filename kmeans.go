@@ -0,0 +1,473 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type (
+	//KMeans partitions rows into K clusters minimizing the squared distance to their center
+	//centers are seeded with k-means++ and the best of NInit runs is kept
+	//a run stops after MaxIter iterations or when the centers move less than Tol
+	//Tol is relative to the mean variance of the columns
+	KMeans struct {
+		K       int
+		NInit   int
+		MaxIter int
+		Tol     float64
+		Seed    int64
+
+		centers [][]float64
+		labels  []int
+		inertia float64
+		nIter   int
+	}
+
+	//MiniBatchKMeans updates the centers with random batches of BatchSize rows
+	//so every iteration costs the same regardless of the number of rows
+	//it stops after MaxIter batches or when the centers move less than Tol
+	MiniBatchKMeans struct {
+		K         int
+		BatchSize int
+		MaxIter   int
+		Tol       float64
+		Seed      int64
+
+		centers [][]float64
+		labels  []int
+		inertia float64
+		nIter   int
+	}
+)
+
+//create k-means with 10 runs of at most 300 iterations and tolerance 1e-4
+func NewKMeans(k int, seed int64) (*KMeans, error) {
+	km := &KMeans{K: k, NInit: 10, MaxIter: 300, Tol: 1e-4, Seed: seed}
+	if err := km.validate(); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+//create mini-batch k-means with batches of 100 rows, at most 100 iterations and tolerance 1e-4
+func NewMiniBatchKMeans(k int, seed int64) (*MiniBatchKMeans, error) {
+	km := &MiniBatchKMeans{K: k, BatchSize: 100, MaxIter: 100, Tol: 1e-4, Seed: seed}
+	if err := km.validate(); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+func (km *KMeans) validate() error {
+	if km.NInit < 1 {
+		return fmt.Errorf("NInit should be at least 1")
+	}
+	return validateClustering(km.K, km.MaxIter, km.Tol)
+}
+
+func (km *MiniBatchKMeans) validate() error {
+	if km.BatchSize < 1 {
+		return fmt.Errorf("Batch size should be at least 1")
+	}
+	return validateClustering(km.K, km.MaxIter, km.Tol)
+}
+
+func validateClustering(k, maxIter int, tol float64) error {
+	if k < 1 {
+		return fmt.Errorf("K should be at least 1")
+	}
+
+	if maxIter < 1 {
+		return fmt.Errorf("MaxIter should be at least 1")
+	}
+
+	if tol < 0 {
+		return fmt.Errorf("Tol should not be negative")
+	}
+
+	return nil
+}
+
+//x should be valid and have at least k rows
+func validateClusterInput(x *Matrix, k int) error {
+	if err := x.validate(); err != nil {
+		return err
+	}
+
+	if k > x.GetRowNumber() {
+		return fmt.Errorf("K(%d) is greater than the number of rows(%d)", k, x.GetRowNumber())
+	}
+
+	return nil
+}
+
+///////////////////////////
+////////HELPERS///////////
+//////////////////////////
+
+func squaredDistance(a, b []float64) float64 {
+	var result float64
+	for i := range a {
+		d := a[i] - b[i]
+		result += d * d
+	}
+	return result
+}
+
+//get the 0-based index of the nearest center and the squared distance to it
+func nearestCenter(row []float64, centers [][]float64) (int, float64) {
+	best, bestDist := 0, math.Inf(1)
+	for i, c := range centers {
+		if d := squaredDistance(row, c); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best, bestDist
+}
+
+//assign every row to its nearest center in parallel
+//return the 0-based labels and the sum of squared distances (inertia)
+func assignCenters(rows, centers [][]float64) ([]int, float64) {
+	labels := make([]int, len(rows))
+	dists := make([]float64, len(rows))
+	parallelRows(len(rows), len(centers)*len(centers[0]), func(from, to int) {
+		for i := from; i < to; i++ {
+			labels[i], dists[i] = nearestCenter(rows[i], centers)
+		}
+	})
+
+	var inertia float64
+	for _, d := range dists {
+		inertia += d
+	}
+	return labels, inertia
+}
+
+//choose k centers among the rows, every next center is drawn
+//with probability proportional to its squared distance to the nearest chosen center
+func kmeansPlusPlus(rows [][]float64, k int, rnd *rand.Rand) [][]float64 {
+	centers := [][]float64{copyFloats(rows[rnd.Intn(len(rows))])}
+
+	dists := make([]float64, len(rows))
+	for i, row := range rows {
+		dists[i] = squaredDistance(row, centers[0])
+	}
+
+	for len(centers) < k {
+		var total float64
+		for _, d := range dists {
+			total += d
+		}
+
+		//all rows are already centers, any of them will do
+		next := rnd.Intn(len(rows))
+		if total > 0 {
+			target := rnd.Float64() * total
+			for i, d := range dists {
+				target -= d
+				if target < 0 {
+					next = i
+					break
+				}
+			}
+		}
+
+		center := copyFloats(rows[next])
+		centers = append(centers, center)
+		for i, row := range rows {
+			dists[i] = math.Min(dists[i], squaredDistance(row, center))
+		}
+	}
+
+	return centers
+}
+
+//mean variance of the columns, used to scale the tolerance
+func meanColumnVariance(rows [][]float64) float64 {
+	var result float64
+	for j := range rows[0] {
+		var sum, sumSq float64
+		for _, row := range rows {
+			sum += row[j]
+			sumSq += row[j] * row[j]
+		}
+		m := sum / float64(len(rows))
+		result += sumSq/float64(len(rows)) - m*m
+	}
+	return result / float64(len(rows[0]))
+}
+
+//sum of the squared distances the centers moved
+func centerShift(old, new [][]float64) float64 {
+	var result float64
+	for i := range old {
+		result += squaredDistance(old[i], new[i])
+	}
+	return result
+}
+
+func cloneRows(rows [][]float64) [][]float64 {
+	result := make([][]float64, len(rows))
+	for i, row := range rows {
+		result[i] = copyFloats(row)
+	}
+	return result
+}
+
+//convert 0-based cluster indices into a vector of labels 1...k
+func clusterLabels(labels []int) *Vector {
+	result := NewZeroVector(len(labels))
+	for i, l := range labels {
+		result.val[i] = float64(l + 1)
+	}
+	return result
+}
+
+//predict the nearest center of every row of x
+func predictCenters(centers [][]float64, x *Matrix) (*Vector, error) {
+	if centers == nil {
+		return nil, ErrNotFitted
+	}
+
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+
+	if x.GetColumnNumber() != len(centers[0]) {
+		return nil, fmt.Errorf("Input dimension(%d) does not agree with the centers(%d)",
+			x.GetColumnNumber(), len(centers[0]))
+	}
+
+	labels, _ := assignCenters(x.rowSlices(), centers)
+	return clusterLabels(labels), nil
+}
+
+///////////////////////////
+////////K-MEANS///////////
+//////////////////////////
+
+//run lloyd's algorithm from the given centers
+//a cluster which loses all of its rows gets the row farthest from its center
+//which hasn't been given to another empty cluster in the same iteration
+func lloyd(rows, centers [][]float64, maxIter int, tol float64) ([][]float64, []int, float64, int) {
+	var labels []int
+	var inertia float64
+
+	itr := 0
+	for itr < maxIter {
+		itr++
+		labels, inertia = assignCenters(rows, centers)
+
+		sums := make([][]float64, len(centers))
+		counts := make([]int, len(centers))
+		for i := range sums {
+			sums[i] = make([]float64, len(rows[0]))
+		}
+		for i, row := range rows {
+			counts[labels[i]]++
+			for j, f := range row {
+				sums[labels[i]][j] += f
+			}
+		}
+
+		//every empty cluster gets a different row
+		newCenters := make([][]float64, len(centers))
+		taken := map[int]bool{}
+		for i := range newCenters {
+			if counts[i] == 0 {
+				far := farthestRow(rows, centers, labels, taken)
+				taken[far] = true
+				newCenters[i] = copyFloats(rows[far])
+				continue
+			}
+			for j := range sums[i] {
+				sums[i][j] /= float64(counts[i])
+			}
+			newCenters[i] = sums[i]
+		}
+
+		shift := centerShift(centers, newCenters)
+		centers = newCenters
+		if shift <= tol {
+			break
+		}
+	}
+
+	labels, inertia = assignCenters(rows, centers)
+	return centers, labels, inertia, itr
+}
+
+//get the row farthest from its center which is not taken yet
+func farthestRow(rows, centers [][]float64, labels []int, taken map[int]bool) int {
+	result, dist := 0, -1.0
+	for i, row := range rows {
+		if taken[i] {
+			continue
+		}
+
+		if d := squaredDistance(row, centers[labels[i]]); d > dist {
+			result, dist = i, d
+		}
+	}
+	return result
+}
+
+//cluster the rows of x and keep the run with the lowest inertia
+func (km *KMeans) Fit(x *Matrix) error {
+	if err := km.validate(); err != nil {
+		return err
+	}
+
+	if err := validateClusterInput(x, km.K); err != nil {
+		return err
+	}
+
+	rows := x.rowSlices()
+	tol := km.Tol * meanColumnVariance(rows)
+	rnd := rand.New(rand.NewSource(km.Seed))
+
+	km.inertia = math.Inf(1)
+	for run := 0; run < km.NInit; run++ {
+		centers, labels, inertia, itr := lloyd(rows, kmeansPlusPlus(rows, km.K, rnd), km.MaxIter, tol)
+		if inertia < km.inertia {
+			km.centers, km.labels, km.inertia, km.nIter = centers, labels, inertia, itr
+		}
+	}
+
+	return nil
+}
+
+//fit x and return the labels of its rows
+func (km *KMeans) FitPredict(x *Matrix) (*Vector, error) {
+	if err := km.Fit(x); err != nil {
+		return nil, err
+	}
+	return km.Labels(), nil
+}
+
+//label every row of x with its nearest center 1...K
+func (km *KMeans) Predict(x *Matrix) (*Vector, error) {
+	return predictCenters(km.centers, x)
+}
+
+//get the centers, row i is the center of label i
+func (km *KMeans) Centers() *Matrix {
+	if km.centers == nil {
+		return nil
+	}
+
+	var m Matrix
+	m.setValue(cloneRows(km.centers))
+	return &m
+}
+
+//get the labels 1...K of the rows used by Fit
+func (km *KMeans) Labels() *Vector {
+	if km.labels == nil {
+		return nil
+	}
+	return clusterLabels(km.labels)
+}
+
+//get the sum of the squared distances of the rows to their center
+func (km *KMeans) Inertia() float64 { return km.inertia }
+
+//get the number of iterations of the best run
+func (km *KMeans) GetIterations() int { return km.nIter }
+
+///////////////////////////
+////////MINI-BATCH////////
+//////////////////////////
+
+//cluster the rows of x with random batches
+//every center moves towards its rows with a learning rate of 1 / number of rows it got so far
+func (km *MiniBatchKMeans) Fit(x *Matrix) error {
+	if err := km.validate(); err != nil {
+		return err
+	}
+
+	if err := validateClusterInput(x, km.K); err != nil {
+		return err
+	}
+
+	rows := x.rowSlices()
+	rnd := rand.New(rand.NewSource(km.Seed))
+	tol := km.Tol * meanColumnVariance(rows)
+
+	//seed the centers on a sample so the initialization doesn't need every row either
+	sampleSize := minInt(len(rows), maxInt(3*km.BatchSize, km.K))
+	sample := make([][]float64, sampleSize)
+	for i, idx := range rnd.Perm(len(rows))[:sampleSize] {
+		sample[i] = rows[idx]
+	}
+	centers := kmeansPlusPlus(sample, km.K, rnd)
+
+	counts := make([]int, km.K)
+	batch := make([][]float64, minInt(km.BatchSize, len(rows)))
+
+	km.nIter = 0
+	for km.nIter < km.MaxIter {
+		km.nIter++
+		for i := range batch {
+			batch[i] = rows[rnd.Intn(len(rows))]
+		}
+
+		old := cloneRows(centers)
+		labels, _ := assignCenters(batch, centers)
+		for i, row := range batch {
+			c := centers[labels[i]]
+			counts[labels[i]]++
+			rate := 1 / float64(counts[labels[i]])
+			for j := range c {
+				c[j] += rate * (row[j] - c[j])
+			}
+		}
+
+		if centerShift(old, centers) <= tol {
+			break
+		}
+	}
+
+	km.centers = centers
+	km.labels, km.inertia = assignCenters(rows, centers)
+	return nil
+}
+
+//fit x and return the labels of its rows
+func (km *MiniBatchKMeans) FitPredict(x *Matrix) (*Vector, error) {
+	if err := km.Fit(x); err != nil {
+		return nil, err
+	}
+	return km.Labels(), nil
+}
+
+//label every row of x with its nearest center 1...K
+func (km *MiniBatchKMeans) Predict(x *Matrix) (*Vector, error) {
+	return predictCenters(km.centers, x)
+}
+
+//get the centers, row i is the center of label i
+func (km *MiniBatchKMeans) Centers() *Matrix {
+	if km.centers == nil {
+		return nil
+	}
+
+	var m Matrix
+	m.setValue(cloneRows(km.centers))
+	return &m
+}
+
+//get the labels 1...K of the rows used by Fit
+func (km *MiniBatchKMeans) Labels() *Vector {
+	if km.labels == nil {
+		return nil
+	}
+	return clusterLabels(km.labels)
+}
+
+//get the sum of the squared distances of the rows to their center
+func (km *MiniBatchKMeans) Inertia() float64 { return km.inertia }
+
+//get the number of processed batches
+func (km *MiniBatchKMeans) GetIterations() int { return km.nIter }
@@ -0,0 +1,186 @@
+package ml
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//create n rows around every center with gaussian noise
+func newBlobs(centers [][]float64, n int, std float64, seed int64) (*Matrix, *Vector) {
+	rnd := rand.New(rand.NewSource(seed))
+
+	var input [][]float64
+	var labels []float64
+	for i := 0; i < n; i++ {
+		for c, center := range centers {
+			row := make([]float64, len(center))
+			for j := range row {
+				row[j] = center[j] + rnd.NormFloat64()*std
+			}
+			input = append(input, row)
+			labels = append(labels, float64(c+1))
+		}
+	}
+
+	x, _ := NewMatrix(input)
+	return x, NewVector(labels)
+}
+
+//every cluster of actual should be exactly one cluster of predicted
+func assertSameClusters(t *testing.T, predicted, actual *Vector) {
+	mapping := map[float64]float64{}
+	for i := 1; i <= actual.GetLength(); i++ {
+		a, p := actual.getSingleValue(i), predicted.getSingleValue(i)
+		if m, ok := mapping[a]; ok {
+			assert.Equal(t, m, p)
+		}
+		mapping[a] = p
+	}
+
+	seen := map[float64]bool{}
+	for _, p := range mapping {
+		assert.False(t, seen[p])
+		seen[p] = true
+	}
+}
+
+var blobCenters = [][]float64{{0, 0}, {10, 10}, {-10, 10}}
+
+func TestKMeansPlusPlus(t *testing.T) {
+	x, _ := newBlobs(blobCenters, 50, 1, 1)
+	rows := x.rowSlices()
+
+	//the seeds should come from different blobs
+	centers := kmeansPlusPlus(rows, 3, rand.New(rand.NewSource(1)))
+	labels, _ := assignCenters(rows, centers)
+	counts := map[int]int{}
+	for _, l := range labels {
+		counts[l]++
+	}
+	assert.Equal(t, 3, len(counts))
+
+	//more centers than distinct rows
+	same := [][]float64{{1, 1}, {1, 1}}
+	assert.Equal(t, 2, len(kmeansPlusPlus(same, 2, rand.New(rand.NewSource(1)))))
+}
+
+//two centers far away from all rows lose them in the first iteration
+//and should be reseeded with different rows
+func TestLloydEmptyClusters(t *testing.T) {
+	rows := [][]float64{{0, 0}, {0, 1}, {10, 0}, {10, 1}, {20, 0}, {20, 1}}
+	centers := [][]float64{{0, 0}, {100, 100}, {100, -100}}
+
+	//the reseeded centers of a single iteration are the two farthest rows
+	first, _, _, _ := lloyd(rows, centers, 1, 0)
+	assert.Equal(t, []float64{20, 1}, first[1])
+	assert.Equal(t, []float64{20, 0}, first[2])
+
+	centers, labels, _, _ := lloyd(rows, centers, 100, 0)
+
+	counts := make([]int, len(centers))
+	for _, l := range labels {
+		counts[l]++
+	}
+	for _, c := range counts {
+		assert.True(t, c > 0)
+	}
+}
+
+func TestKMeans(t *testing.T) {
+	_, err := NewKMeans(0, 1)
+	assert.Error(t, err)
+
+	km, _ := NewKMeans(3, 1)
+	_, err = km.Predict(NewConstantMatrix(1, 2, 0))
+	assert.Equal(t, ErrNotFitted, err)
+	assert.Nil(t, km.Centers())
+	assert.Nil(t, km.Labels())
+
+	//K can't be greater than the number of rows
+	assert.Error(t, km.Fit(NewConstantMatrix(2, 2, 0)))
+
+	x, y := newBlobs(blobCenters, 50, 1, 1)
+	labels, err := km.FitPredict(x)
+	assert.NoError(t, err)
+	assertSameClusters(t, labels, y)
+	fmt.Printf("KMeans inertia: %v after %v iterations\n", km.Inertia(), km.GetIterations())
+
+	//every center is near one of the blob centers
+	centers := km.Centers()
+	assert.Equal(t, 3, centers.GetRowNumber())
+	for i := 1; i <= 3; i++ {
+		_, dist := nearestCenter(centers.val[i].val, blobCenters)
+		assert.True(t, dist < 0.5)
+	}
+
+	//predict new rows
+	query, _ := NewMatrix(blobCenters)
+	result, err := km.Predict(query)
+	assert.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		center := centers.val[int(result.getSingleValue(i))].val
+		assert.InDeltaSlice(t, blobCenters[i-1], center, 0.5)
+	}
+
+	_, err = km.Predict(NewConstantMatrix(1, 3, 0))
+	assert.Error(t, err)
+
+	//the same seed gives the same result
+	km2, _ := NewKMeans(3, 1)
+	km2.Fit(x)
+	assert.Equal(t, km.Labels(), km2.Labels())
+	assert.Equal(t, km.Inertia(), km2.Inertia())
+
+	//inertia only gets smaller with more clusters
+	km4, _ := NewKMeans(4, 1)
+	km4.Fit(x)
+	assert.True(t, km4.Inertia() < km.Inertia())
+}
+
+func TestKMeansFromExData(t *testing.T) {
+	x, _ := LoadNewMatrix("data1.csv", ":", "1:2")
+
+	single, _ := NewKMeans(2, 1)
+	single.NInit = 1
+	single.Fit(x)
+
+	best, _ := NewKMeans(2, 1)
+	best.Fit(x)
+
+	fmt.Printf("KMeans inertia on data1.csv: %v\n", best.Inertia())
+	assert.True(t, best.Inertia() <= single.Inertia())
+
+	labels := best.Labels()
+	for i := 1; i <= labels.GetLength(); i++ {
+		l := labels.getSingleValue(i)
+		assert.True(t, l == 1 || l == 2)
+	}
+}
+
+func TestMiniBatchKMeans(t *testing.T) {
+	_, err := NewMiniBatchKMeans(3, 1)
+	assert.NoError(t, err)
+
+	km := &MiniBatchKMeans{K: 3, BatchSize: 0, MaxIter: 10}
+	assert.Error(t, km.Fit(NewConstantMatrix(5, 2, 0)))
+
+	x, y := newBlobs(blobCenters, 1000, 1, 2)
+	km, _ = NewMiniBatchKMeans(3, 1)
+	labels, err := km.FitPredict(x)
+	assert.NoError(t, err)
+
+	full, _ := NewKMeans(3, 1)
+	full.Fit(x)
+	fmt.Printf("MiniBatchKMeans inertia: %v, KMeans inertia: %v\n", km.Inertia(), full.Inertia())
+	assert.True(t, km.Inertia() < full.Inertia()*1.05)
+
+	//the blobs are far enough apart for every row to be in the right cluster
+	assertSameClusters(t, labels, y)
+
+	result, err := km.Predict(km.Centers())
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 3}, result.val)
+}
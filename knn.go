@@ -0,0 +1,284 @@
+package ml
+
+import "fmt"
+
+type (
+	//NeighborWeights selects how the k nearest rows contribute to a prediction
+	NeighborWeights int
+
+	//KNNClassifier predicts the label with the most (weighted) votes among the K nearest training rows
+	//P is the parameter of the minkowski metric, BruteForce disables the kd-tree
+	KNNClassifier struct {
+		K          int
+		Metric     DistanceMetric
+		P          float64
+		Weights    NeighborWeights
+		BruteForce bool
+
+		index   *neighborIndex
+		y       []float64
+		classes []float64
+	}
+
+	//KNNRegressor predicts the (weighted) mean of the K nearest training rows
+	//P is the parameter of the minkowski metric, BruteForce disables the kd-tree
+	KNNRegressor struct {
+		K          int
+		Metric     DistanceMetric
+		P          float64
+		Weights    NeighborWeights
+		BruteForce bool
+
+		index *neighborIndex
+		y     []float64
+	}
+)
+
+const (
+	//every neighbor counts the same
+	UniformWeights NeighborWeights = iota
+	//neighbors count with 1 / distance, rows at distance 0 outweigh all others
+	DistanceWeights
+)
+
+//create a knn classifier, P of the minkowski metric defaults to 2
+func NewKNNClassifier(k int, metric DistanceMetric, weights NeighborWeights) (*KNNClassifier, error) {
+	if err := validateKNN(k, metric, 2, weights); err != nil {
+		return nil, err
+	}
+
+	return &KNNClassifier{K: k, Metric: metric, P: 2, Weights: weights}, nil
+}
+
+//create a knn regressor, P of the minkowski metric defaults to 2
+func NewKNNRegressor(k int, metric DistanceMetric, weights NeighborWeights) (*KNNRegressor, error) {
+	if err := validateKNN(k, metric, 2, weights); err != nil {
+		return nil, err
+	}
+
+	return &KNNRegressor{K: k, Metric: metric, P: 2, Weights: weights}, nil
+}
+
+func validateKNN(k int, metric DistanceMetric, p float64, weights NeighborWeights) error {
+	if k < 1 {
+		return fmt.Errorf("K should be at least 1")
+	}
+
+	if weights != UniformWeights && weights != DistanceWeights {
+		return fmt.Errorf("Unknown neighbor weights %d", weights)
+	}
+
+	return validateMetric(metric, p)
+}
+
+//build the neighbor index of x, both x and y are copied
+func fitNeighbors(x *Matrix, y *Vector, k int, metric DistanceMetric, p float64, weights NeighborWeights, bruteForce bool) (*neighborIndex, []float64, error) {
+	if err := validateKNN(k, metric, p, weights); err != nil {
+		return nil, nil, err
+	}
+
+	if err := x.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	if x.GetRowNumber() != y.GetLength() {
+		return nil, nil, fmt.Errorf("X and Y row number are not the same")
+	}
+
+	if k > x.GetRowNumber() {
+		return nil, nil, fmt.Errorf("K(%d) is greater than the number of rows(%d)", k, x.GetRowNumber())
+	}
+
+	index, err := newNeighborIndex(x, metric, p, bruteForce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return index, copyFloats(y.val), nil
+}
+
+//find the k nearest training rows for every row of x
+//rows are queried in parallel and the result is in the same order as x
+func queryNeighbors(index *neighborIndex, x *Matrix, k int) ([][]neighbor, error) {
+	if index == nil {
+		return nil, ErrNotFitted
+	}
+
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+
+	if x.GetColumnNumber() != len(index.rows[0]) {
+		return nil, fmt.Errorf("Input dimension(%d) does not agree with training data(%d)",
+			x.GetColumnNumber(), len(index.rows[0]))
+	}
+
+	rows := x.rowSlices()
+	result := make([][]neighbor, len(rows))
+	parallelRows(len(rows), len(index.rows)*len(rows[0]), func(from, to int) {
+		for i := from; i < to; i++ {
+			result[i] = index.query(rows[i], k)
+		}
+	})
+
+	return result, nil
+}
+
+//weight of every neighbor, if one of them has distance 0
+//only the rows at distance 0 count
+func neighborWeights(neighbors []neighbor, weights NeighborWeights) []float64 {
+	result := make([]float64, len(neighbors))
+	if weights == UniformWeights {
+		for i := range result {
+			result[i] = 1
+		}
+		return result
+	}
+
+	exact := false
+	for i, n := range neighbors {
+		if n.dist == 0 {
+			result[i], exact = 1, true
+		}
+	}
+	if exact {
+		return result
+	}
+
+	for i, n := range neighbors {
+		result[i] = 1 / n.dist
+	}
+	return result
+}
+
+//get the 1-based indices and distances of the k nearest training rows of every row of x
+func kneighbors(index *neighborIndex, x *Matrix, k int) ([][]int, [][]float64, error) {
+	neighbors, err := queryNeighbors(index, x, k)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	indices := make([][]int, len(neighbors))
+	dists := make([][]float64, len(neighbors))
+	for i, ns := range neighbors {
+		for _, n := range ns {
+			indices[i] = append(indices[i], n.index)
+			dists[i] = append(dists[i], n.dist)
+		}
+	}
+	return indices, dists, nil
+}
+
+///////////////////////////
+////////CLASSIFIER////////
+//////////////////////////
+
+func (c *KNNClassifier) Fit(x *Matrix, y *Vector) error {
+	index, labels, err := fitNeighbors(x, y, c.K, c.Metric, c.P, c.Weights, c.BruteForce)
+	if err != nil {
+		return err
+	}
+
+	c.index, c.y = index, labels
+	c.classes = uniqueSorted(labels)
+	return nil
+}
+
+//get the sorted labels seen by Fit
+func (c *KNNClassifier) Classes() []float64 { return copyFloats(c.classes) }
+
+//get the 1-based indices and distances of the K nearest training rows of every row of x
+func (c *KNNClassifier) KNeighbors(x *Matrix) ([][]int, [][]float64, error) {
+	return kneighbors(c.index, x, c.K)
+}
+
+//calculate the share of the (weighted) votes of every class
+//row i of the result belongs to row i of x and column j to Classes()[j-1]
+func (c *KNNClassifier) PredictProba(x *Matrix) (*Matrix, error) {
+	neighbors, err := queryNeighbors(c.index, x, c.K)
+	if err != nil {
+		return nil, err
+	}
+
+	classIndex := make(map[float64]int)
+	for i, class := range c.classes {
+		classIndex[class] = i
+	}
+
+	input := make([][]float64, len(neighbors))
+	for i, ns := range neighbors {
+		votes := make([]float64, len(c.classes))
+		var total float64
+		for j, w := range neighborWeights(ns, c.Weights) {
+			votes[classIndex[c.y[ns[j].index-1]]] += w
+			total += w
+		}
+		for j := range votes {
+			votes[j] /= total
+		}
+		input[i] = votes
+	}
+
+	return NewMatrix(input)
+}
+
+//predict the class with the greatest share of votes
+//ties are broken in favor of the smaller label
+func (c *KNNClassifier) Predict(x *Matrix) (*Vector, error) {
+	proba, err := c.PredictProba(x)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewZeroVector(proba.GetRowNumber())
+	for i := 1; i <= proba.GetRowNumber(); i++ {
+		best := 0
+		for j, p := range proba.val[i].val {
+			if p > proba.val[i].val[best] {
+				best = j
+			}
+		}
+		result.val[i-1] = c.classes[best]
+	}
+
+	return result, nil
+}
+
+///////////////////////////
+////////REGRESSOR/////////
+//////////////////////////
+
+func (r *KNNRegressor) Fit(x *Matrix, y *Vector) error {
+	index, values, err := fitNeighbors(x, y, r.K, r.Metric, r.P, r.Weights, r.BruteForce)
+	if err != nil {
+		return err
+	}
+
+	r.index, r.y = index, values
+	return nil
+}
+
+//get the 1-based indices and distances of the K nearest training rows of every row of x
+func (r *KNNRegressor) KNeighbors(x *Matrix) ([][]int, [][]float64, error) {
+	return kneighbors(r.index, x, r.K)
+}
+
+//predict the (weighted) mean of the K nearest training rows
+func (r *KNNRegressor) Predict(x *Matrix) (*Vector, error) {
+	neighbors, err := queryNeighbors(r.index, x, r.K)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewZeroVector(len(neighbors))
+	for i, ns := range neighbors {
+		var sum, total float64
+		for j, w := range neighborWeights(ns, r.Weights) {
+			sum += w * r.y[ns[j].index-1]
+			total += w
+		}
+		result.val[i] = sum / total
+	}
+
+	return result, nil
+}
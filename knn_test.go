@@ -0,0 +1,139 @@
+package ml
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKNN(t *testing.T) {
+	_, err := NewKNNClassifier(0, EuclideanMetric, UniformWeights)
+	assert.Error(t, err)
+
+	_, err = NewKNNRegressor(3, DistanceMetric(10), UniformWeights)
+	assert.Error(t, err)
+
+	_, err = NewKNNRegressor(3, EuclideanMetric, NeighborWeights(5))
+	assert.Error(t, err)
+
+	c, err := NewKNNClassifier(3, MinkowskiMetric, DistanceWeights)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), c.P)
+
+	_, err = c.Predict(NewConstantMatrix(1, 2, 0))
+	assert.Equal(t, ErrNotFitted, err)
+
+	//K can't be greater than the number of rows
+	x, _ := NewMatrix([][]float64{{1, 2}, {3, 4}})
+	y := NewVector([]float64{0, 1})
+	assert.Error(t, c.Fit(x, y))
+
+	c.K = 2
+	assert.Error(t, c.Fit(x, NewVector([]float64{1})))
+	assert.NoError(t, c.Fit(x, y))
+
+	//number of columns should agree with the training data
+	_, err = c.Predict(NewConstantMatrix(1, 3, 0))
+	assert.Error(t, err)
+}
+
+func TestKNNClassifier(t *testing.T) {
+	x, _ := NewMatrix([][]float64{{0, 0}, {0, 1}, {1, 0}, {5, 5}, {5, 6}, {6, 5}})
+	y := NewVector([]float64{0, 0, 0, 1, 1, 1})
+
+	c, _ := NewKNNClassifier(3, EuclideanMetric, UniformWeights)
+	assert.NoError(t, c.Fit(x, y))
+	assert.Equal(t, []float64{0, 1}, c.Classes())
+
+	query, _ := NewMatrix([][]float64{{0.2, 0.2}, {5.5, 5.5}, {3, 3}})
+	result, err := c.Predict(query)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 1, 0}, result.val)
+
+	proba, _ := c.PredictProba(query)
+	assert.Equal(t, []float64{1, 0}, proba.val[1].val)
+	assert.Equal(t, []float64{0, 1}, proba.val[2].val)
+
+	indices, dists, err := c.KNeighbors(query)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, indices[0])
+	assert.InDelta(t, 0.28284, dists[0][0], 1e-5)
+
+	//cosine distance only looks at the direction
+	cx, _ := NewMatrix([][]float64{{1, 0}, {10, 1}, {0, 1}, {1, 10}})
+	cy := NewVector([]float64{0, 0, 1, 1})
+	cosine, _ := NewKNNClassifier(1, CosineMetric, UniformWeights)
+	cosine.Fit(cx, cy)
+	cquery, _ := NewMatrix([][]float64{{100, 2}, {0.1, 0.5}})
+	result, _ = cosine.Predict(cquery)
+	assert.Equal(t, []float64{0, 1}, result.val)
+
+	//with distance weights an exact match decides alone
+	c.K, c.Weights = 6, DistanceWeights
+	c.Fit(x, y)
+	result, _ = c.Predict(x)
+	assert.Equal(t, y.val, result.val)
+}
+
+func TestKNNClassifierFromExData(t *testing.T) {
+	//non-linear boundary of data2.csv without polynomial features
+	x, _ := LoadNewMatrix("data2.csv", ":", "1:2")
+	y, _ := LoadNewVector("data2.csv", ":", "3")
+
+	folds, _ := NewStratifiedKFold(5, true, 1)
+	for _, metric := range []DistanceMetric{EuclideanMetric, ManhattanMetric, MinkowskiMetric} {
+		result, err := CrossValidate(func() Estimator {
+			return &KNNClassifier{K: 5, Metric: metric, P: 3, Weights: DistanceWeights}
+		}, x, y, folds, Accuracy)
+		assert.NoError(t, err)
+
+		fmt.Printf("kNN accuracy on data2.csv with %v metric: %v\n", metric, result.Mean)
+		assert.True(t, result.Mean > 0.6)
+	}
+}
+
+func TestKNNRegressor(t *testing.T) {
+	x, _ := NewMatrix([][]float64{{1}, {2}, {3}, {10}})
+	y := NewVector([]float64{1, 2, 3, 10})
+
+	r, _ := NewKNNRegressor(2, ManhattanMetric, UniformWeights)
+	assert.NoError(t, r.Fit(x, y))
+
+	query, _ := NewMatrix([][]float64{{1.5}, {2.75}})
+	result, err := r.Predict(query)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{1.5, 2.5}, result.val, 1e-12)
+
+	//2.75 is 0.75 away from 2 and 0.25 away from 3
+	r.Weights = DistanceWeights
+	result, _ = r.Predict(query)
+	assert.InDeltaSlice(t, []float64{1.5, 2.75}, result.val, 1e-12)
+
+	//changing x after Fit doesn't affect the model
+	x.Calculate(func(f float64) float64 { return 0 })
+	result, _ = r.Predict(query)
+	assert.InDeltaSlice(t, []float64{1.5, 2.75}, result.val, 1e-12)
+}
+
+func TestKNNRegressorFromExData(t *testing.T) {
+	xTrain, xTest, yTrain, yTest, _ := SequentialTrainTestSplit(loadData3(), loadData3Y(), 0.8)
+
+	r, _ := NewKNNRegressor(5, EuclideanMetric, UniformWeights)
+	assert.NoError(t, r.Fit(xTrain, yTrain))
+
+	result, _ := r.Predict(xTest)
+	mae, _ := MeanAbsoluteError(result, yTest)
+	fmt.Printf("kNN mean absolute error on data3.csv: %v\n", mae)
+	assert.True(t, mae < 5)
+}
+
+func loadData3() *Matrix {
+	x, _ := LoadNewMatrix("data3.csv", ":", "1")
+	return x
+}
+
+func loadData3Y() *Vector {
+	y, _ := LoadNewVector("data3.csv", ":", "2")
+	return y
+}
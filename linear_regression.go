@@ -1,17 +1,31 @@
 package ml
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"time"
 )
 
 type (
 	LinReg struct {
 		x      *Matrix
+		sx     *SparseMatrix
 		y      *Vector
 		theta  *Vector
 		alpha  float64
 		lambda float64
+		opt    sgdState
+	}
+
+	//LinearRegressionEstimator trains a LinReg with zero initial theta
+	//so it can be used as an Estimator e.g. by CrossValidate
+	LinearRegressionEstimator struct {
+		Alpha      float64
+		Lambda     float64
+		Iterations int
+
+		model *LinReg
 	}
 )
 
@@ -51,7 +65,40 @@ func NewLinearRegression(x *Matrix, y, theta *Vector, alpha float64) (*LinReg, e
 	return &LinReg{
 		x:     x,
 		y:     y,
-		theta: theta,
+		theta: NewVector(copyFloats(theta.val)),
+		alpha: alpha}, nil
+}
+
+//create new linear regression object trained on a sparse matrix
+//x is never densified and doesn't need the 1's column
+//the validations are the same as for NewLinearRegression
+func NewSparseLinearRegression(x *SparseMatrix, y, theta *Vector, alpha float64) (*LinReg, error) {
+	if err := validateSparseRegressionInput(x, y, theta, alpha); err != nil {
+		return nil, err
+	}
+
+	return &LinReg{
+		sx:    x,
+		y:     y,
+		theta: NewVector(copyFloats(theta.val)),
+		alpha: alpha}, nil
+}
+
+//create new linear regression object without training data for online learning
+//it learns only from batches with PartialFit or UpdateGradStream
+//theta sets the number of features, its first element is for the 1's column
+//alpha should be greater than 0
+func NewOnlineLinearRegression(theta *Vector, alpha float64) (*LinReg, error) {
+	if theta.GetLength() == 0 {
+		return nil, ErrEmptyVector
+	}
+
+	if alpha <= 0 {
+		return nil, fmt.Errorf("Learning rate alpha should be greater than 0")
+	}
+
+	return &LinReg{
+		theta: NewVector(copyFloats(theta.val)),
 		alpha: alpha}, nil
 }
 
@@ -79,7 +126,7 @@ func (lr *LinReg) h(input *Vector) float64 {
 }
 
 //calculate the cost func J from gradient descent struct
-//formula for cost function is: 1/2m (sigma(1...m)(h(xi) - yi) ^2  + lambda. sigma(1..n)thetaj^2)
+//formula for cost function is: 1/2m (sigma(1...m)(h(xi) - yi) ^2  + lambda. sigma(2..n)thetaj^2)
 func (lr *LinReg) cost(index int) float64 {
 	//calculate h(xi) - y and then use its power of 2
 	res := lr.h(lr.x.getRowVector(index)) - lr.y.getSingleValue(index)
@@ -90,15 +137,26 @@ func (lr *LinReg) cost(index int) float64 {
 func (lr *LinReg) regParam() float64 {
 	m, n := lr.y.GetLength(), lr.theta.GetLength()
 
+	//theta1 of the 1's column is not regularized, the same as in derivTheta
+	//and sparseGradient, so the cost agrees with the gradient used for training
 	var regParam float64
-	for j := 1; j <= n; j++ {
+	for j := 2; j <= n; j++ {
 		regParam += math.Pow(lr.theta.getSingleValue(j), 2)
 	}
 
 	return regParam * lr.lambda / (2 * float64(m))
 }
 
+//an online model without training data returns NaN
 func (lr *LinReg) CostFunc() float64 {
+	if lr.y == nil {
+		return math.NaN()
+	}
+
+	if lr.sx != nil {
+		return lr.sparseCostFunc()
+	}
+
 	m := lr.y.GetLength()
 
 	var result float64
@@ -131,7 +189,28 @@ func (lr *LinReg) derivTheta(index int) float64 {
 	return sigma / float64(m)
 }
 
+//same cost function as CostFunc for a sparse x
+func (lr *LinReg) sparseCostFunc() float64 {
+	m := lr.y.GetLength()
+
+	var result float64
+	for key, h := range sparseLinearCombination(lr.sx, lr.theta.val) {
+		result += math.Pow(h-lr.y.val[key], 2)
+	}
+
+	return result/(2*float64(m)) + lr.regParam()
+}
+
+//an online model without training data returns nil
 func (lr *LinReg) CalculateGrad() *Vector {
+	if lr.y == nil {
+		return nil
+	}
+
+	if lr.sx != nil {
+		return sparseGradient(lr.sx, lr.y, lr.theta, lr.lambda, func(z float64) float64 { return z })
+	}
+
 	n := lr.x.GetColumnNumber()
 
 	var newTheta []float64
@@ -145,36 +224,190 @@ func (lr *LinReg) CalculateGrad() *Vector {
 //update theta as much as itr iterrations
 func (lr *LinReg) updateGrad() {
 	grad := lr.CalculateGrad()
-	//fmt.Println(grad)
+	//theta - alpha * grad is stored as a new vector
+	//so a theta obtained before keeps its value
 	grad.MultiplyVariable(-1 * lr.alpha)
-	lr.theta.AddVector(grad)
+	grad.AddVector(lr.theta)
+	lr.theta = grad
 }
 
+//an online model without training data is not changed
 func (lr *LinReg) UpdateGrad(itr int) {
+	if lr.y == nil {
+		return
+	}
+
 	for i := 0; i < itr; i++ {
 		lr.updateGrad()
 	}
 }
 
+//same as UpdateGrad but checks ctx before every iteration
+//if ctx is done the training stops and ctx.Err() is returned
+//theta keeps the value of the last finished iteration so the model can still be used
+//itr lesser than 0 trains until ctx is done, which needs a cancellable ctx
+//return the number of finished iterations
+func (lr *LinReg) UpdateGradContext(ctx context.Context, itr int) (int, error) {
+	if lr.y == nil {
+		return 0, ErrNoTrainingData
+	}
+
+	return trainContext(ctx, itr, lr.updateGrad)
+}
+
+//train as much as itr iterations but stop after budget has elapsed
+//return context.DeadlineExceeded if the budget wasn't enough
+func (lr *LinReg) UpdateGradWithBudget(budget time.Duration, itr int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	return lr.UpdateGradContext(ctx, itr)
+}
+
+//create a model of a single batch which shares theta and lambda with lr
+//the batch gets the 1's column, dimensions should agree with theta
+//the training data of lr is not changed
+func (lr *LinReg) newBatch(x *Matrix, y *Vector) (*LinReg, error) {
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+
+	if x.GetRowNumber() != y.GetLength() {
+		return nil, fmt.Errorf("X and Y row number are not the same")
+	}
+
+	if x.GetColumnNumber()+1 != lr.theta.GetLength() {
+		return nil, fmt.Errorf("Number of X and theta features are not the same")
+	}
+
+	x.AddConstantVectorToFirst(1)
+	return &LinReg{x: x, y: y, theta: lr.theta, lambda: lr.lambda}, nil
+}
+
+//calculate the cost function on a batch instead of the training data
+//x shouldn't contain the 1's column and it is not changed
+func (lr *LinReg) BatchCost(x *Matrix, y *Vector) (float64, error) {
+	if err := x.validate(); err != nil {
+		return 0, err
+	}
+
+	batch, err := lr.newBatch(x.Clone(), y)
+	if err != nil {
+		return 0, err
+	}
+
+	return batch.CostFunc(), nil
+}
+
+//set the momentum of PartialFit steps, 0 means plain gradient descent
+//momentum should be in range [0, 1)
+func (lr *LinReg) SetMomentum(momentum float64) error {
+	if err := validateMomentum(momentum); err != nil {
+		return err
+	}
+
+	lr.opt.momentum = momentum
+	return nil
+}
+
+//set the learning rate decay of PartialFit steps
+//the learning rate of a step is alpha / (1 + decay * steps)
+func (lr *LinReg) SetDecay(decay float64) error {
+	if err := validateDecay(decay); err != nil {
+		return err
+	}
+
+	lr.opt.decay = decay
+	return nil
+}
+
+//get a copy of the current theta
+func (lr *LinReg) GetTheta() *Vector { return NewVector(copyFloats(lr.theta.val)) }
+
+//replace theta by a copy of the given vector, the length can't change
+func (lr *LinReg) SetTheta(theta *Vector) error {
+	if theta.GetLength() != lr.theta.GetLength() {
+		return ErrVectorFalseDimension
+	}
+
+	lr.theta = NewVector(copyFloats(theta.val))
+	return nil
+}
+
+//get the number of PartialFit steps taken so far
+func (lr *LinReg) GetSteps() int { return lr.opt.steps }
+
+//forget the momentum and the number of steps of PartialFit
+func (lr *LinReg) ResetOptimizer() {
+	lr.opt.velocity = nil
+	lr.opt.steps = 0
+}
+
+//do a single step on the gradient of the batch and return the batch with the new theta
+func (lr *LinReg) partialFit(x *Matrix, y *Vector) (*LinReg, error) {
+	batch, err := lr.newBatch(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	lr.theta = lr.opt.step(lr.theta, batch.CalculateGrad(), lr.alpha)
+	batch.theta = lr.theta
+	return batch, nil
+}
+
+//do a single gradient step on a new batch while keeping the optimizer state
+//so a model can continue learning as new rows arrive
+//x shouldn't contain the 1's column and it is not changed
+//the training data of the model is not changed either
+func (lr *LinReg) PartialFit(x *Matrix, y *Vector) error {
+	if err := x.validate(); err != nil {
+		return err
+	}
+
+	_, err := lr.partialFit(x.Clone(), y)
+	return err
+}
+
+//train with mini-batch gradient descent over a csv stream
+//the stream is read from the first row for every epoch and every batch
+//of batchSize rows is a single PartialFit step, so only one batch is in memory at a time
+//col syntax is the same as for LoadNewMatrix, the training data of the model is not changed
+func (lr *LinReg) UpdateGradStream(s *CSVStream, xCol, yCol string, batchSize, epochs int) error {
+	return streamBatches(s, xCol, yCol, batchSize, epochs, func(x *Matrix, y *Vector) error {
+		_, err := lr.partialFit(x, y)
+		return err
+	})
+}
+
 //calculate the result as a set of y vector
+//x is not modified, the 1's column is added internally
 func (lr *LinReg) CalculateResult(x *Matrix) (*Vector, error) {
-	//first add 1's column vector to x matrix
-	for key, val := range x.val {
-		newx := []float64{1}
-		newx = append(newx, val.val...)
-		x.val[key] = NewVector(newx)
+	return lr.Snapshot().Predict(x)
+}
+
+//train a new model on x and y
+//x is copied since the constructor adds the 1's column to it
+func (e *LinearRegressionEstimator) Fit(x *Matrix, y *Vector) error {
+	if err := x.validate(); err != nil {
+		return err
 	}
 
-	//validate both length
-	if x.GetColumnNumber() != lr.theta.GetLength() {
-		return nil, fmt.Errorf("Input vector dimension(%d) does not agree with theta(%d)",
-			x.GetColumnNumber(), lr.theta.GetLength())
+	theta := NewZeroVector(x.GetColumnNumber() + 1)
+	lr, err := NewLinearRegression(x.Clone(), y, theta, e.Alpha)
+	if err != nil {
+		return err
 	}
 
-	var result []float64
-	for i := 1; i <= x.GetRowNumber(); i++ {
-		result = append(result, lr.h(x.getRowVector(i)))
+	lr.AddRegularizationFactor(e.Lambda)
+	lr.UpdateGrad(e.Iterations)
+	e.model = lr
+	return nil
+}
+
+func (e *LinearRegressionEstimator) Predict(x *Matrix) (*Vector, error) {
+	if e.model == nil {
+		return nil, ErrNotFitted
 	}
 
-	return NewVector(result), nil
+	return e.model.CalculateResult(x)
 }
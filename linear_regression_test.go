@@ -1,6 +1,7 @@
 package ml
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"testing"
@@ -40,18 +41,81 @@ func TestLinearRegressionCostFunction(t *testing.T) {
 	thetaverif := lr.theta
 	lrverif, _ := NewLinearRegression(xverif, yverif, thetaverif, 1)
 
-	//var predictTrue, predictFalse int
-	var totalErr float64
+	predicted := NewZeroVector(xverif.GetRowNumber())
 	for i := 1; i <= xverif.GetRowNumber(); i++ {
 		xvec, _ := xverif.GetRowVector(i)
 		res := lrverif.h(xvec)
 		realVal := yverif.getSingleValue(i)
-		err := math.Abs(res - realVal)
-		totalErr += err
+		predicted.setSingleValue(i, res)
 
 		fmt.Printf("Result[%.2f] : %.2f | y = %.2f | error: %.2f\n",
-			xvec.getSingleValue(2), res, realVal, err)
+			xvec.getSingleValue(2), res, realVal, math.Abs(res-realVal))
 	}
-	fmt.Printf("Average error: %.2f\n", totalErr/float64(xverif.GetRowNumber()))
+
+	mae, err := MeanAbsoluteError(predicted, yverif)
+	assert.NoError(t, err)
+	fmt.Printf("Average error: %.2f\n", mae)
 	fmt.Println("")
 }
+
+//theta1 is not part of the regularization, with x = [1, 2], y = [1, 2] and theta = [1, 1]
+//the cost is (1^2 + 1^2) / 4 + 1 / 4 * 1^2 = 0.75
+func TestLinearRegressionRegularizedCost(t *testing.T) {
+	x, _ := NewMatrix([][]float64{{1}, {2}})
+	sx, _ := NewSparseMatrixFromMatrix(x)
+
+	lr, _ := NewLinearRegression(x, NewVector([]float64{1, 2}), NewVector([]float64{1, 1}), 0.01)
+	lr.AddRegularizationFactor(1)
+	assert.Equal(t, 0.75, lr.CostFunc())
+
+	//the sparse cost is the same
+	slr, _ := NewSparseLinearRegression(sx, NewVector([]float64{1, 2}), NewVector([]float64{1, 1}), 0.01)
+	slr.AddRegularizationFactor(1)
+	assert.Equal(t, 0.75, slr.CostFunc())
+}
+
+//non positive iterations don't train at all
+func TestLinearRegressionUpdateGradNegative(t *testing.T) {
+	x, _ := LoadNewMatrix("data3.csv", "1:80", "1")
+	y, _ := LoadNewVector("data3.csv", "1:80", "2")
+	lr, _ := NewLinearRegression(x, y, NewZeroVector(2), 0.01)
+
+	lr.UpdateGrad(-1)
+	lr.UpdateGrad(0)
+	assert.Equal(t, []float64{0, 0}, lr.theta.val)
+}
+
+func TestLinearRegressionUpdateGradContext(t *testing.T) {
+	file := "data3.csv"
+	x, _ := LoadNewMatrix(file, "1:80", "1")
+	y, _ := LoadNewVector(file, "1:80", "2")
+
+	lr, err := NewLinearRegression(x, y, NewZeroVector(2), 0.01)
+	assert.NoError(t, err)
+
+	//canceled before the first iteration
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, err := lr.UpdateGradContext(ctx, 100)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, []float64{0, 0}, lr.theta.val)
+
+	n, err = lr.UpdateGradContext(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n)
+
+	//a context which is never done can't train without limit
+	n, err = lr.UpdateGradContext(context.Background(), -1)
+	assert.Error(t, err)
+	assert.Equal(t, 0, n)
+
+	//the budget runs out long before the iterations do
+	//the model keeps the partially trained theta
+	cost := lr.CostFunc()
+	n, err = lr.UpdateGradWithBudget(20*time.Millisecond, -1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, n > 0)
+	assert.True(t, lr.CostFunc() < cost)
+	fmt.Printf("Linear regression iterations within 20ms: %d\n", n)
+}
@@ -3,17 +3,31 @@
 package ml
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"time"
 )
 
 type (
 	LReg struct {
 		x      *Matrix
+		sx     *SparseMatrix
 		y      *Vector
 		theta  *Vector
 		alpha  float64
 		lambda float64
+		opt    sgdState
+	}
+
+	//LogisticRegressionEstimator trains a LReg with zero initial theta
+	//so it can be used as an Estimator e.g. by CrossValidate
+	LogisticRegressionEstimator struct {
+		Alpha      float64
+		Lambda     float64
+		Iterations int
+
+		model *LReg
 	}
 )
 
@@ -52,17 +66,63 @@ func NewLogisticRegression(x *Matrix, y, theta *Vector, alpha float64) (*LReg, e
 	}
 
 	//5. validation
+	if err := validateBinaryLabels(y); err != nil {
+		return nil, err
+	}
+
+	return &LReg{
+		x:     x,
+		y:     y,
+		theta: NewVector(copyFloats(theta.val)),
+		alpha: alpha}, nil
+}
+
+//all member of y should be either 1 or 0
+func validateBinaryLabels(y *Vector) error {
 	for i := 1; i <= y.GetLength(); i++ {
 		val := y.getSingleValue(i)
 		if val != float64(0) && val != float64(1) {
-			return nil, fmt.Errorf("Value of y should be either 0 or 1")
+			return fmt.Errorf("Value of y should be either 0 or 1")
 		}
 	}
 
+	return nil
+}
+
+//create new logistic regression object trained on a sparse matrix
+//x is never densified and doesn't need the 1's column
+//the validations are the same as for NewLogisticRegression
+func NewSparseLogisticRegression(x *SparseMatrix, y, theta *Vector, alpha float64) (*LReg, error) {
+	if err := validateSparseRegressionInput(x, y, theta, alpha); err != nil {
+		return nil, err
+	}
+
+	if err := validateBinaryLabels(y); err != nil {
+		return nil, err
+	}
+
 	return &LReg{
-		x:     x,
+		sx:    x,
 		y:     y,
-		theta: theta,
+		theta: NewVector(copyFloats(theta.val)),
+		alpha: alpha}, nil
+}
+
+//create new logistic regression object without training data for online learning
+//it learns only from batches with PartialFit or UpdateGradStream
+//theta sets the number of features, its first element is for the 1's column
+//alpha should be greater than 0
+func NewOnlineLogisticRegression(theta *Vector, alpha float64) (*LReg, error) {
+	if theta.GetLength() == 0 {
+		return nil, ErrEmptyVector
+	}
+
+	if alpha <= 0 {
+		return nil, fmt.Errorf("Learning rate alpha should be greater than 0")
+	}
+
+	return &LReg{
+		theta: NewVector(copyFloats(theta.val)),
 		alpha: alpha}, nil
 }
 
@@ -105,19 +165,30 @@ func (lr *LReg) cost(index int) float64 {
 }
 
 //calculate the regularization parameter
-//the formula is lambda / (2* m) * sigma(1..n)thetaj^2
+//the formula is lambda / (2* m) * sigma(2..n)thetaj^2
 func (lr *LReg) regParam() float64 {
 	m, n := lr.y.GetLength(), lr.theta.GetLength()
 
+	//theta1 of the 1's column is not regularized, the same as in derivTheta
+	//and sparseGradient, so the cost agrees with the gradient used for training
 	var regParam float64
-	for j := 1; j <= n; j++ {
+	for j := 2; j <= n; j++ {
 		regParam += math.Pow(lr.theta.getSingleValue(j), 2)
 	}
 
 	return regParam * lr.lambda / (2 * float64(m))
 }
 
+//an online model without training data returns NaN
 func (lr *LReg) CostFunc() float64 {
+	if lr.y == nil {
+		return math.NaN()
+	}
+
+	if lr.sx != nil {
+		return lr.sparseCostFunc()
+	}
+
 	m := lr.y.GetLength()
 	regParam := lr.regParam()
 
@@ -152,7 +223,33 @@ func (lr *LReg) derivTheta(index int) float64 {
 	return sigma / float64(m)
 }
 
+//same cost function as CostFunc for a sparse x
+func (lr *LReg) sparseCostFunc() float64 {
+	m := lr.y.GetLength()
+
+	var result float64
+	for key, z := range sparseLinearCombination(lr.sx, lr.theta.val) {
+		switch lr.y.val[key] {
+		case 0:
+			result -= math.Log(1 - sigm(z))
+		case 1:
+			result -= math.Log(sigm(z))
+		}
+	}
+
+	return result/float64(m) + lr.regParam()
+}
+
+//an online model without training data returns nil
 func (lr *LReg) CalculateGrad() *Vector {
+	if lr.y == nil {
+		return nil
+	}
+
+	if lr.sx != nil {
+		return sparseGradient(lr.sx, lr.y, lr.theta, lr.lambda, sigm)
+	}
+
 	n := lr.x.GetColumnNumber()
 
 	var newTheta []float64
@@ -167,19 +264,176 @@ func (lr *LReg) CalculateGrad() *Vector {
 //update the theta as much as itr iterrations
 func (lr *LReg) updateGrad(debug bool) {
 	grad := lr.CalculateGrad()
+	//theta - alpha * grad is stored as a new vector
+	//so a theta obtained before keeps its value
 	grad.MultiplyVariable(-1 * lr.alpha)
-	lr.theta.AddVector(grad)
+	grad.AddVector(lr.theta)
+	lr.theta = grad
 	if debug {
 		fmt.Println(lr.CostFunc())
 	}
 }
 
+//an online model without training data is not changed
 func (lr *LReg) UpdateGrad(itr int, debug bool) {
+	if lr.y == nil {
+		return
+	}
+
 	for i := 0; i < itr; i++ {
 		lr.updateGrad(debug)
 	}
 }
 
+//same as UpdateGrad but checks ctx before every iteration
+//if ctx is done the training stops and ctx.Err() is returned
+//theta keeps the value of the last finished iteration so the model can still be used
+//itr lesser than 0 trains until ctx is done, which needs a cancellable ctx
+//return the number of finished iterations
+func (lr *LReg) UpdateGradContext(ctx context.Context, itr int, debug bool) (int, error) {
+	if lr.y == nil {
+		return 0, ErrNoTrainingData
+	}
+
+	return trainContext(ctx, itr, func() { lr.updateGrad(debug) })
+}
+
+//train as much as itr iterations but stop after budget has elapsed
+//return context.DeadlineExceeded if the budget wasn't enough
+func (lr *LReg) UpdateGradWithBudget(budget time.Duration, itr int, debug bool) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	return lr.UpdateGradContext(ctx, itr, debug)
+}
+
+//create a model of a single batch which shares theta and lambda with lr
+//the batch gets the 1's column, dimensions should agree with theta
+//the training data of lr is not changed
+func (lr *LReg) newBatch(x *Matrix, y *Vector) (*LReg, error) {
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+
+	if x.GetRowNumber() != y.GetLength() {
+		return nil, fmt.Errorf("X and Y row number are not the same")
+	}
+
+	if x.GetColumnNumber()+1 != lr.theta.GetLength() {
+		return nil, fmt.Errorf("Number of X and theta features are not the same")
+	}
+
+	if err := validateBinaryLabels(y); err != nil {
+		return nil, err
+	}
+
+	x.AddConstantVectorToFirst(1)
+	return &LReg{x: x, y: y, theta: lr.theta, lambda: lr.lambda}, nil
+}
+
+//calculate the cost function on a batch instead of the training data
+//x shouldn't contain the 1's column and it is not changed
+func (lr *LReg) BatchCost(x *Matrix, y *Vector) (float64, error) {
+	if err := x.validate(); err != nil {
+		return 0, err
+	}
+
+	batch, err := lr.newBatch(x.Clone(), y)
+	if err != nil {
+		return 0, err
+	}
+
+	return batch.CostFunc(), nil
+}
+
+//set the momentum of PartialFit steps, 0 means plain gradient descent
+//momentum should be in range [0, 1)
+func (lr *LReg) SetMomentum(momentum float64) error {
+	if err := validateMomentum(momentum); err != nil {
+		return err
+	}
+
+	lr.opt.momentum = momentum
+	return nil
+}
+
+//set the learning rate decay of PartialFit steps
+//the learning rate of a step is alpha / (1 + decay * steps)
+func (lr *LReg) SetDecay(decay float64) error {
+	if err := validateDecay(decay); err != nil {
+		return err
+	}
+
+	lr.opt.decay = decay
+	return nil
+}
+
+//get a copy of the current theta
+func (lr *LReg) GetTheta() *Vector { return NewVector(copyFloats(lr.theta.val)) }
+
+//replace theta by a copy of the given vector, the length can't change
+func (lr *LReg) SetTheta(theta *Vector) error {
+	if theta.GetLength() != lr.theta.GetLength() {
+		return ErrVectorFalseDimension
+	}
+
+	lr.theta = NewVector(copyFloats(theta.val))
+	return nil
+}
+
+//get the number of PartialFit steps taken so far
+func (lr *LReg) GetSteps() int { return lr.opt.steps }
+
+//forget the momentum and the number of steps of PartialFit
+func (lr *LReg) ResetOptimizer() {
+	lr.opt.velocity = nil
+	lr.opt.steps = 0
+}
+
+//do a single step on the gradient of the batch and return the batch with the new theta
+func (lr *LReg) partialFit(x *Matrix, y *Vector) (*LReg, error) {
+	batch, err := lr.newBatch(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	lr.theta = lr.opt.step(lr.theta, batch.CalculateGrad(), lr.alpha)
+	batch.theta = lr.theta
+	return batch, nil
+}
+
+//do a single gradient step on a new batch while keeping the optimizer state
+//so a model can continue learning as new rows arrive
+//x shouldn't contain the 1's column and it is not changed
+//the training data of the model is not changed either
+func (lr *LReg) PartialFit(x *Matrix, y *Vector) error {
+	if err := x.validate(); err != nil {
+		return err
+	}
+
+	_, err := lr.partialFit(x.Clone(), y)
+	return err
+}
+
+//train with mini-batch gradient descent over a csv stream
+//the stream is read from the first row for every epoch and every batch
+//of batchSize rows is a single PartialFit step, so only one batch is in memory at a time
+//col syntax is the same as for LoadNewMatrix, the training data of the model is not changed
+//debug prints the cost of every batch after its step
+func (lr *LReg) UpdateGradStream(s *CSVStream, xCol, yCol string, batchSize, epochs int, debug bool) error {
+	return streamBatches(s, xCol, yCol, batchSize, epochs, func(x *Matrix, y *Vector) error {
+		batch, err := lr.partialFit(x, y)
+		if err != nil {
+			return err
+		}
+
+		if debug {
+			fmt.Println(batch.CostFunc())
+		}
+		return nil
+	})
+}
+
 //calculate the result as set of y vector
 //if predicted y is lesser than 0.5 then predict it as 0, and 1 otherwise
 func (lr *LReg) CalculateResult(x *Matrix) (*Vector, error) {
@@ -200,3 +454,30 @@ func (lr *LReg) CalculateResult(x *Matrix) (*Vector, error) {
 
 	return NewVector(result), nil
 }
+
+//train a new model on x and y
+//x is copied since the constructor adds the 1's column to it
+func (e *LogisticRegressionEstimator) Fit(x *Matrix, y *Vector) error {
+	if err := x.validate(); err != nil {
+		return err
+	}
+
+	theta := NewZeroVector(x.GetColumnNumber() + 1)
+	lr, err := NewLogisticRegression(x.Clone(), y, theta, e.Alpha)
+	if err != nil {
+		return err
+	}
+
+	lr.AddRegularizationFactor(e.Lambda)
+	lr.UpdateGrad(e.Iterations, false)
+	e.model = lr
+	return nil
+}
+
+func (e *LogisticRegressionEstimator) Predict(x *Matrix) (*Vector, error) {
+	if e.model == nil {
+		return nil, ErrNotFitted
+	}
+
+	return e.model.Snapshot().Predict(x)
+}
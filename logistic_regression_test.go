@@ -1,7 +1,9 @@
 package ml
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -293,3 +295,35 @@ func TestLogisticRegressionWithAddedFeatures(t *testing.T) {
 
 	fmt.Println("")
 }
+
+//theta1 is not part of the regularization, with x = [0], y = [1] and theta = [1, 2]
+//the cost is -log(sigm(1)) + 1 / 2 * 2^2
+func TestLogisticRegressionRegularizedCost(t *testing.T) {
+	x, _ := NewMatrix([][]float64{{0}})
+	lreg, _ := NewLogisticRegression(x, NewVector([]float64{1}), NewVector([]float64{1, 2}), 0.01)
+	lreg.AddRegularizationFactor(1)
+	assert.InDelta(t, 2-math.Log(sigm(1)), lreg.CostFunc(), 1e-12)
+}
+
+func TestLogisticRegressionUpdateGradContext(t *testing.T) {
+	lreg := newLogisticReg(1.5)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	n, err := lreg.UpdateGradContext(ctx, -1, false)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, n > 0)
+
+	n, err = lreg.UpdateGradWithBudget(time.Minute, 5, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	//UpdateGrad with negative iterations returns immediately
+	theta := copyFloats(lreg.theta.val)
+	lreg.UpdateGrad(-1, false)
+	assert.Equal(t, theta, lreg.theta.val)
+
+	_, err = lreg.UpdateGradContext(context.Background(), -1, false)
+	assert.Error(t, err)
+}
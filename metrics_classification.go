@@ -0,0 +1,346 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+type (
+	//ConfusionMatrix counts how often every actual label was predicted as every label
+	//row is the actual label and column the predicted one, both in the order of Labels
+	ConfusionMatrix struct {
+		Labels []float64
+		counts [][]int
+	}
+
+	//Average decides how per label scores are combined into a single value
+	Average int
+)
+
+const (
+	//BinaryAverage only reports the score of label 1
+	BinaryAverage Average = iota
+	//MicroAverage counts true/false positives/negatives over all labels
+	MicroAverage
+	//MacroAverage is the unweighted mean of the score of every label
+	MacroAverage
+	//WeightedAverage is the mean weighted by the number of actual samples of every label
+	WeightedAverage
+)
+
+//the positive label for BinaryAverage, same as y of logistic regression
+const positiveLabel = float64(1)
+
+///////////////////////////
+////////CONFUSION/////////
+//////////////////////////
+
+//create a new confusion matrix out of predicted and actual labels
+//labels are the sorted union of both vectors
+func NewConfusionMatrix(predicted, actual *Vector) (*ConfusionMatrix, error) {
+	if err := validateMetricInput(predicted, actual); err != nil {
+		return nil, err
+	}
+
+	set := map[float64]bool{}
+	for i := 1; i <= actual.GetLength(); i++ {
+		set[actual.getSingleValue(i)] = true
+		set[predicted.getSingleValue(i)] = true
+	}
+
+	var labels []float64
+	for label := range set {
+		labels = append(labels, label)
+	}
+	sort.Float64s(labels)
+
+	counts := make([][]int, len(labels))
+	for i := range counts {
+		counts[i] = make([]int, len(labels))
+	}
+
+	cm := &ConfusionMatrix{Labels: labels, counts: counts}
+	for i := 1; i <= actual.GetLength(); i++ {
+		a := cm.labelIndex(actual.getSingleValue(i))
+		p := cm.labelIndex(predicted.getSingleValue(i))
+		cm.counts[a][p]++
+	}
+
+	return cm, nil
+}
+
+//get the 0-based index of a label, -1 if the label doesn't exist
+func (cm *ConfusionMatrix) labelIndex(label float64) int {
+	k := sort.SearchFloat64s(cm.Labels, label)
+	if k < len(cm.Labels) && cm.Labels[k] == label {
+		return k
+	}
+	return -1
+}
+
+//get how often actual label was predicted as predicted label
+func (cm *ConfusionMatrix) GetCount(actual, predicted float64) int {
+	a, p := cm.labelIndex(actual), cm.labelIndex(predicted)
+	if a < 0 || p < 0 {
+		return 0
+	}
+	return cm.counts[a][p]
+}
+
+//get the total number of samples
+func (cm *ConfusionMatrix) total() int {
+	var result int
+	for _, row := range cm.counts {
+		for _, c := range row {
+			result += c
+		}
+	}
+	return result
+}
+
+//get true positives, false positives, false negatives and true negatives
+//of the label with index k
+func (cm *ConfusionMatrix) counters(k int) (tp, fp, fn, tn int) {
+	tp = cm.counts[k][k]
+	for i := range cm.Labels {
+		if i == k {
+			continue
+		}
+		fp += cm.counts[i][k]
+		fn += cm.counts[k][i]
+	}
+	tn = cm.total() - tp - fp - fn
+	return
+}
+
+//get the number of actual samples of the label with index k
+func (cm *ConfusionMatrix) support(k int) int {
+	var result int
+	for _, c := range cm.counts[k] {
+		result += c
+	}
+	return result
+}
+
+//divide and return 0 instead of NaN if the denominator is 0
+func safeDivide(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
+
+//score functions of a single label
+func precisionOf(tp, fp, fn, tn int) float64 {
+	return safeDivide(float64(tp), float64(tp+fp))
+}
+
+func recallOf(tp, fp, fn, tn int) float64 {
+	return safeDivide(float64(tp), float64(tp+fn))
+}
+
+func f1Of(tp, fp, fn, tn int) float64 {
+	return safeDivide(float64(2*tp), float64(2*tp+fp+fn))
+}
+
+func specificityOf(tp, fp, fn, tn int) float64 {
+	return safeDivide(float64(tn), float64(tn+fp))
+}
+
+//combine the score of every label as selected by avg
+func (cm *ConfusionMatrix) average(score func(tp, fp, fn, tn int) float64, avg Average) (float64, error) {
+	switch avg {
+	case BinaryAverage:
+		k := cm.labelIndex(positiveLabel)
+		if k < 0 {
+			return 0, nil
+		}
+		return score(cm.counters(k)), nil
+	case MicroAverage:
+		var tp, fp, fn, tn int
+		for k := range cm.Labels {
+			t, f, n, tneg := cm.counters(k)
+			tp, fp, fn, tn = tp+t, fp+f, fn+n, tn+tneg
+		}
+		return score(tp, fp, fn, tn), nil
+	case MacroAverage, WeightedAverage:
+		var result, weights float64
+		for k := range cm.Labels {
+			w := float64(1)
+			if avg == WeightedAverage {
+				w = float64(cm.support(k))
+			}
+			result += w * score(cm.counters(k))
+			weights += w
+		}
+		return safeDivide(result, weights), nil
+	}
+
+	return 0, fmt.Errorf("Unknown average %d", avg)
+}
+
+func (cm *ConfusionMatrix) String() string {
+	sprint := "Actual \\ Predicted"
+	for _, label := range cm.Labels {
+		sprint += fmt.Sprintf("\t%.2f", label)
+	}
+	sprint += "\n"
+
+	for k, label := range cm.Labels {
+		sprint += fmt.Sprintf("%.2f\t\t", label)
+		for _, c := range cm.counts[k] {
+			sprint += fmt.Sprintf("\t%d", c)
+		}
+		sprint += "\n"
+	}
+
+	return sprint
+}
+
+///////////////////////////
+////////METRICS///////////
+//////////////////////////
+
+//accuracy is the share of correctly predicted labels
+func Accuracy(predicted, actual *Vector) (float64, error) {
+	cm, err := NewConfusionMatrix(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	var correct int
+	for k := range cm.Labels {
+		correct += cm.counts[k][k]
+	}
+
+	return float64(correct) / float64(cm.total()), nil
+}
+
+//precision is tp / (tp + fp), i.e. how many of the predicted positives are correct
+func Precision(predicted, actual *Vector, avg Average) (float64, error) {
+	cm, err := NewConfusionMatrix(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	return cm.average(precisionOf, avg)
+}
+
+//recall is tp / (tp + fn), i.e. how many of the actual positives are found
+func Recall(predicted, actual *Vector, avg Average) (float64, error) {
+	cm, err := NewConfusionMatrix(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	return cm.average(recallOf, avg)
+}
+
+//F1 is the harmonic mean of precision and recall: 2tp / (2tp + fp + fn)
+func F1Score(predicted, actual *Vector, avg Average) (float64, error) {
+	cm, err := NewConfusionMatrix(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	return cm.average(f1Of, avg)
+}
+
+//specificity is tn / (tn + fp), i.e. the recall of the negatives
+func Specificity(predicted, actual *Vector, avg Average) (float64, error) {
+	cm, err := NewConfusionMatrix(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	return cm.average(specificityOf, avg)
+}
+
+//Matthews correlation coefficient between -1 and 1, 0 is a random prediction
+//the multiclass formula is (c*s - sigma(pk*tk)) / sqrt((s^2 - sigma(pk^2)) * (s^2 - sigma(tk^2)))
+//with c correct predictions, s samples, pk predictions and tk actual samples of label k
+func MatthewsCorrCoef(predicted, actual *Vector) (float64, error) {
+	cm, err := NewConfusionMatrix(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	var c, sumPT, sumP2, sumT2 float64
+	s := float64(cm.total())
+	for k := range cm.Labels {
+		c += float64(cm.counts[k][k])
+
+		var p float64
+		for i := range cm.Labels {
+			p += float64(cm.counts[i][k])
+		}
+		t := float64(cm.support(k))
+
+		sumPT += p * t
+		sumP2 += p * p
+		sumT2 += t * t
+	}
+
+	return safeDivide(c*s-sumPT, math.Sqrt((s*s-sumP2)*(s*s-sumT2))), nil
+}
+
+//log loss of predicted probabilities of label 1 against actual 0/1 labels
+//the formula is -1/m * sigma(1...m)(yi*log(pi) + (1-yi)*log(1-pi))
+//probabilities are clipped to [1e-15, 1-1e-15] so the result is always finite
+func LogLoss(probabilities, actual *Vector) (float64, error) {
+	if err := validateMetricInput(probabilities, actual); err != nil {
+		return 0, err
+	}
+
+	const eps = 1e-15
+
+	var result float64
+	for i := 1; i <= actual.GetLength(); i++ {
+		y := actual.getSingleValue(i)
+		if y != 0 && y != 1 {
+			return 0, fmt.Errorf("Value of y should be either 0 or 1")
+		}
+
+		p := math.Min(math.Max(probabilities.getSingleValue(i), eps), 1-eps)
+		result += y*math.Log(p) + (1-y)*math.Log(1-p)
+	}
+
+	return -1 * result / float64(actual.GetLength()), nil
+}
+
+//create a text report with precision, recall, F1 and support of every label
+//followed by accuracy, macro and weighted averages
+func ClassificationReport(predicted, actual *Vector) (string, error) {
+	cm, err := NewConfusionMatrix(predicted, actual)
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%12s %10s %10s %10s %10s", "", "precision", "recall", "f1-score", "support"))
+	for k, label := range cm.Labels {
+		tp, fp, fn, tn := cm.counters(k)
+		lines = append(lines, fmt.Sprintf("%12.2f %10.2f %10.2f %10.2f %10d", label,
+			precisionOf(tp, fp, fn, tn), recallOf(tp, fp, fn, tn), f1Of(tp, fp, fn, tn), cm.support(k)))
+	}
+	lines = append(lines, "")
+
+	accuracy, _ := Accuracy(predicted, actual)
+	lines = append(lines, fmt.Sprintf("%12s %10s %10s %10.2f %10d", "accuracy", "", "", accuracy, cm.total()))
+
+	for _, avg := range []Average{MacroAverage, WeightedAverage} {
+		name := "macro avg"
+		if avg == WeightedAverage {
+			name = "weighted avg"
+		}
+
+		p, _ := cm.average(precisionOf, avg)
+		r, _ := cm.average(recallOf, avg)
+		f, _ := cm.average(f1Of, avg)
+		lines = append(lines, fmt.Sprintf("%12s %10.2f %10.2f %10.2f %10d", name, p, r, f, cm.total()))
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
@@ -0,0 +1,109 @@
+package ml
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfusionMatrix(t *testing.T) {
+	_, err := NewConfusionMatrix(NewVector([]float64{1}), NewVector([]float64{1, 0}))
+	assert.Equal(t, ErrVectorFalseDimension, err)
+
+	actual := NewVector([]float64{2, 0, 2, 2, 0, 1})
+	predicted := NewVector([]float64{0, 0, 2, 2, 0, 2})
+
+	cm, err := NewConfusionMatrix(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 1, 2}, cm.Labels)
+	assert.Equal(t, 2, cm.GetCount(0, 0))
+	assert.Equal(t, 1, cm.GetCount(1, 2))
+	assert.Equal(t, 1, cm.GetCount(2, 0))
+	assert.Equal(t, 2, cm.GetCount(2, 2))
+	assert.Equal(t, 0, cm.GetCount(3, 2))
+	fmt.Println(cm)
+}
+
+func TestBinaryClassificationMetrics(t *testing.T) {
+	actual := NewVector([]float64{1, 1, 1, 1, 0, 0, 0, 0, 0, 0})
+	predicted := NewVector([]float64{1, 1, 1, 0, 1, 0, 0, 0, 0, 0})
+
+	acc, err := Accuracy(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.8, acc)
+
+	p, err := Precision(predicted, actual, BinaryAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, p)
+
+	r, err := Recall(predicted, actual, BinaryAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, r)
+
+	f1, err := F1Score(predicted, actual, BinaryAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, f1)
+
+	s, err := Specificity(predicted, actual, BinaryAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.83333", fmt.Sprintf("%.5f", s))
+
+	mcc, err := MatthewsCorrCoef(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.58333", fmt.Sprintf("%.5f", mcc))
+
+	//no positive prediction at all shouldn't divide by zero
+	p, err = Precision(NewZeroVector(10), actual, BinaryAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), p)
+}
+
+func TestMulticlassClassificationMetrics(t *testing.T) {
+	actual := NewVector([]float64{0, 1, 2, 0, 1, 2})
+	predicted := NewVector([]float64{0, 2, 1, 0, 0, 1})
+
+	p, err := Precision(predicted, actual, MacroAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.22222", fmt.Sprintf("%.5f", p))
+
+	p, err = Precision(predicted, actual, MicroAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.33333", fmt.Sprintf("%.5f", p))
+
+	f1, err := F1Score(predicted, actual, MacroAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.26667", fmt.Sprintf("%.5f", f1))
+
+	f1, err = F1Score(predicted, actual, WeightedAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.26667", fmt.Sprintf("%.5f", f1))
+
+	r, err := Recall(predicted, actual, MacroAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.33333", fmt.Sprintf("%.5f", r))
+
+	_, err = Recall(predicted, actual, Average(10))
+	assert.Error(t, err)
+
+	report, err := ClassificationReport(predicted, actual)
+	assert.NoError(t, err)
+	fmt.Println(report)
+}
+
+func TestLogLoss(t *testing.T) {
+	actual := NewVector([]float64{0, 1, 1, 0})
+	proba := NewVector([]float64{0.1, 0.8, 0.7, 0.35})
+
+	loss, err := LogLoss(proba, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.27899", fmt.Sprintf("%.5f", loss))
+
+	_, err = LogLoss(proba, NewVector([]float64{0, 1, 2, 0}))
+	assert.Error(t, err)
+
+	//clipping keeps a certain but wrong prediction finite
+	loss, err = LogLoss(NewVector([]float64{1}), NewVector([]float64{0}))
+	assert.NoError(t, err)
+	assert.Equal(t, "34.53958", fmt.Sprintf("%.5f", loss))
+}
@@ -0,0 +1,284 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+//internal metrics compare the rows of x with their cluster labels
+//as validation x should be valid, have a label for every row and 2...n-1 clusters
+//distances between rows are euclidean
+func validateClusterMetricInput(x *Matrix, labels *Vector) ([]int, int, error) {
+	if err := x.validate(); err != nil {
+		return nil, 0, err
+	}
+
+	if x.GetRowNumber() != labels.GetLength() {
+		return nil, 0, fmt.Errorf("X and labels row number are not the same")
+	}
+
+	clusters, k := clusterIndices(labels)
+	if k < 2 || k > x.GetRowNumber()-1 {
+		return nil, 0, fmt.Errorf("Number of clusters(%d) should be in range [2, %d]", k, x.GetRowNumber()-1)
+	}
+
+	return clusters, k, nil
+}
+
+//map every label to the 0-based index of its cluster
+//return the indices and the number of clusters
+func clusterIndices(labels *Vector) ([]int, int) {
+	classes := uniqueSorted(labels.val)
+	index := make(map[float64]int)
+	for i, c := range classes {
+		index[c] = i
+	}
+
+	result := make([]int, labels.GetLength())
+	for i, l := range labels.val {
+		result[i] = index[l]
+	}
+	return result, len(classes)
+}
+
+//get the mean of the rows of every cluster and the cluster sizes
+func clusterCentroids(rows [][]float64, clusters []int, k int) ([][]float64, []int) {
+	centroids := make([][]float64, k)
+	for i := range centroids {
+		centroids[i] = make([]float64, len(rows[0]))
+	}
+
+	sizes := make([]int, k)
+	for i, row := range rows {
+		sizes[clusters[i]]++
+		for j, f := range row {
+			centroids[clusters[i]][j] += f
+		}
+	}
+
+	for i, c := range centroids {
+		for j := range c {
+			c[j] /= float64(sizes[i])
+		}
+	}
+	return centroids, sizes
+}
+
+//silhouette of row i is (b - a) / max(a, b) between -1 and 1
+//a is the mean distance to the other rows of its cluster and
+//b is the mean distance to the rows of the nearest other cluster
+//rows which are alone in their cluster get 0
+func SilhouetteSamples(x *Matrix, labels *Vector) (*Vector, error) {
+	clusters, k, err := validateClusterMetricInput(x, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := x.rowSlices()
+	_, sizes := clusterCentroids(rows, clusters, k)
+
+	result := NewZeroVector(len(rows))
+	parallelRows(len(rows), len(rows)*len(rows[0]), func(from, to int) {
+		for i := from; i < to; i++ {
+			sums := make([]float64, k)
+			for j, row := range rows {
+				sums[clusters[j]] += math.Sqrt(squaredDistance(rows[i], row))
+			}
+
+			own := clusters[i]
+			if sizes[own] == 1 {
+				continue
+			}
+
+			a := sums[own] / float64(sizes[own]-1)
+			b := math.Inf(1)
+			for c := range sums {
+				if c != own {
+					b = math.Min(b, sums[c]/float64(sizes[c]))
+				}
+			}
+			result.val[i] = (b - a) / math.Max(a, b)
+		}
+	})
+
+	return result, nil
+}
+
+//silhouette score is the mean silhouette of all rows, greater is better
+func SilhouetteScore(x *Matrix, labels *Vector) (float64, error) {
+	samples, err := SilhouetteSamples(x, labels)
+	if err != nil {
+		return 0, err
+	}
+	return mean(samples), nil
+}
+
+//davies-bouldin index is 1/k * sigma(1...k) max(j != i) (si + sj) / dij
+//si is the mean distance of the rows of cluster i to its centroid and
+//dij the distance between the centroids of i and j, lesser is better
+func DaviesBouldinScore(x *Matrix, labels *Vector) (float64, error) {
+	clusters, k, err := validateClusterMetricInput(x, labels)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := x.rowSlices()
+	centroids, sizes := clusterCentroids(rows, clusters, k)
+
+	scatter := make([]float64, k)
+	for i, row := range rows {
+		scatter[clusters[i]] += math.Sqrt(squaredDistance(row, centroids[clusters[i]]))
+	}
+	for i := range scatter {
+		scatter[i] /= float64(sizes[i])
+	}
+
+	var result float64
+	for i := 0; i < k; i++ {
+		var worst float64
+		for j := 0; j < k; j++ {
+			//clusters with the same centroid are skipped
+			d := math.Sqrt(squaredDistance(centroids[i], centroids[j]))
+			if j == i || d == 0 {
+				continue
+			}
+			worst = math.Max(worst, (scatter[i]+scatter[j])/d)
+		}
+		result += worst
+	}
+
+	return result / float64(k), nil
+}
+
+//calinski-harabasz score is (B / (k - 1)) / (W / (n - k))
+//B is the squared distance of the cluster centroids to the overall mean weighted by cluster size and
+//W the squared distance of every row to its cluster centroid, greater is better
+//if W is 0 the score is 1
+func CalinskiHarabaszScore(x *Matrix, labels *Vector) (float64, error) {
+	clusters, k, err := validateClusterMetricInput(x, labels)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := x.rowSlices()
+	centroids, sizes := clusterCentroids(rows, clusters, k)
+	overall, _ := clusterCentroids(rows, make([]int, len(rows)), 1)
+
+	var between, within float64
+	for i, c := range centroids {
+		between += float64(sizes[i]) * squaredDistance(c, overall[0])
+	}
+	for i, row := range rows {
+		within += squaredDistance(row, centroids[clusters[i]])
+	}
+
+	if within == 0 {
+		return 1, nil
+	}
+
+	n := float64(len(rows))
+	return (between / float64(k-1)) / (within / (n - float64(k))), nil
+}
+
+//count the rows of every pair of predicted and actual cluster
+//return the table and its row (predicted) and column (actual) sums
+func contingencyTable(predicted, actual *Vector) ([][]int, []int, []int) {
+	p, kp := clusterIndices(predicted)
+	a, ka := clusterIndices(actual)
+
+	table := make([][]int, kp)
+	for i := range table {
+		table[i] = make([]int, ka)
+	}
+
+	rowSums, colSums := make([]int, kp), make([]int, ka)
+	for i := range p {
+		table[p[i]][a[i]]++
+		rowSums[p[i]]++
+		colSums[a[i]]++
+	}
+	return table, rowSums, colSums
+}
+
+//number of pairs out of n
+func comb2(n int) float64 { return float64(n) * float64(n-1) / 2 }
+
+//adjusted rand index is the share of row pairs on which both labelings agree
+//adjusted for chance, 1 means the same partition and around 0 a random one
+//label values don't matter, only which rows share a label
+func AdjustedRandScore(predicted, actual *Vector) (float64, error) {
+	if err := validateMetricInput(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	table, rowSums, colSums := contingencyTable(predicted, actual)
+
+	var index, sumRows, sumCols float64
+	for _, row := range table {
+		for _, n := range row {
+			index += comb2(n)
+		}
+	}
+	for _, n := range rowSums {
+		sumRows += comb2(n)
+	}
+	for _, n := range colSums {
+		sumCols += comb2(n)
+	}
+
+	//a single row has no pairs, so there is nothing to disagree on
+	pairs := comb2(actual.GetLength())
+	if pairs == 0 {
+		return 1, nil
+	}
+
+	expected := sumRows * sumCols / pairs
+	max := (sumRows + sumCols) / 2
+	//both are a single cluster or every row is its own cluster
+	//these are the only cases where the denominator is 0
+	if max == expected {
+		return 1, nil
+	}
+
+	return (index - expected) / (max - expected), nil
+}
+
+//normalized mutual information is I(P, A) / ((H(P) + H(A)) / 2) between 0 and 1
+//I is the mutual information and H the entropy of the labelings
+//label values don't matter, only which rows share a label
+func NormalizedMutualInfo(predicted, actual *Vector) (float64, error) {
+	if err := validateMetricInput(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	table, rowSums, colSums := contingencyTable(predicted, actual)
+	n := float64(actual.GetLength())
+
+	entropy := func(sums []int) float64 {
+		var result float64
+		for _, s := range sums {
+			p := float64(s) / n
+			result -= p * math.Log(p)
+		}
+		return result
+	}
+
+	var mi float64
+	for i, row := range table {
+		for j, c := range row {
+			if c == 0 {
+				continue
+			}
+			nij := float64(c)
+			mi += nij / n * math.Log(n*nij/(float64(rowSums[i])*float64(colSums[j])))
+		}
+	}
+
+	hp, ha := entropy(rowSums), entropy(colSums)
+	//both are a single cluster
+	if hp == 0 && ha == 0 {
+		return 1, nil
+	}
+
+	return math.Max(mi, 0) / ((hp + ha) / 2), nil
+}
@@ -0,0 +1,120 @@
+package ml
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSilhouette(t *testing.T) {
+	x, _ := NewMatrix([][]float64{{0}, {1}, {4}, {5}})
+	labels := NewVector([]float64{1, 1, 2, 2})
+
+	samples, err := SilhouetteSamples(x, labels)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.77778, 0.71429, 0.71429, 0.77778}, samples.val, 1e-5)
+
+	score, _ := SilhouetteScore(x, labels)
+	assert.InDelta(t, 0.74603, score, 1e-5)
+
+	//a row alone in its cluster gets 0
+	samples, _ = SilhouetteSamples(x, NewVector([]float64{1, 1, 1, 2}))
+	assert.Equal(t, float64(0), samples.getSingleValue(4))
+
+	_, err = SilhouetteScore(x, NewVector([]float64{1, 1, 1, 1}))
+	assert.Error(t, err)
+
+	_, err = SilhouetteScore(x, NewVector([]float64{1, 2, 3, 4}))
+	assert.Error(t, err)
+
+	_, err = SilhouetteScore(x, NewVector([]float64{1, 2}))
+	assert.Error(t, err)
+}
+
+func TestDaviesBouldinAndCalinskiHarabasz(t *testing.T) {
+	x, _ := NewMatrix([][]float64{{0}, {1}, {4}, {5}})
+	labels := NewVector([]float64{1, 1, 2, 2})
+
+	db, err := DaviesBouldinScore(x, labels)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.25, db, 1e-12)
+
+	ch, err := CalinskiHarabaszScore(x, labels)
+	assert.NoError(t, err)
+	assert.InDelta(t, 32, ch, 1e-12)
+
+	//a worse clustering has a greater davies-bouldin and lesser calinski-harabasz score
+	worse := NewVector([]float64{1, 2, 1, 2})
+	db2, _ := DaviesBouldinScore(x, worse)
+	ch2, _ := CalinskiHarabaszScore(x, worse)
+	assert.True(t, db2 > db)
+	assert.True(t, ch2 < ch)
+
+	_, err = DaviesBouldinScore(x, NewVector([]float64{1, 1, 1, 1}))
+	assert.Error(t, err)
+}
+
+func TestAdjustedRandAndNMI(t *testing.T) {
+	actual := NewVector([]float64{1, 1, 2, 2})
+
+	//label values don't matter
+	ari, err := AdjustedRandScore(NewVector([]float64{2, 2, 1, 1}), actual)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), ari)
+
+	nmi, err := NormalizedMutualInfo(NewVector([]float64{5, 5, 3, 3}), actual)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, nmi, 1e-12)
+
+	predicted := NewVector([]float64{1, 1, 1, 2})
+	ari, _ = AdjustedRandScore(predicted, actual)
+	assert.InDelta(t, 0, ari, 1e-12)
+
+	nmi, _ = NormalizedMutualInfo(predicted, actual)
+	assert.InDelta(t, 0.34371, nmi, 1e-5)
+
+	//single clusters are the same partition
+	ones := NewConstantVector(4, 1)
+	ari, _ = AdjustedRandScore(ones, ones)
+	nmi, _ = NormalizedMutualInfo(ones, ones)
+	assert.Equal(t, float64(1), ari)
+	assert.Equal(t, float64(1), nmi)
+
+	//a single row or singleton clusters in both labelings have a denominator of 0
+	ari, err = AdjustedRandScore(NewVector([]float64{3}), NewVector([]float64{1}))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), ari)
+	ari, _ = AdjustedRandScore(NewVector([]float64{1, 2, 3}), NewVector([]float64{4, 5, 6}))
+	assert.Equal(t, float64(1), ari)
+
+	_, err = AdjustedRandScore(NewVector([]float64{1}), actual)
+	assert.Equal(t, ErrVectorFalseDimension, err)
+	_, err = NormalizedMutualInfo(NewVector([]float64{}), NewVector([]float64{}))
+	assert.Equal(t, ErrEmptyVector, err)
+}
+
+func TestClusterMetricsOnKMeans(t *testing.T) {
+	x, y := newBlobs(blobCenters, 50, 1, 1)
+	km, _ := NewKMeans(3, 1)
+	labels, _ := km.FitPredict(x)
+
+	silhouette, _ := SilhouetteScore(x, labels)
+	db, _ := DaviesBouldinScore(x, labels)
+	ch, _ := CalinskiHarabaszScore(x, labels)
+	ari, _ := AdjustedRandScore(labels, y)
+	nmi, _ := NormalizedMutualInfo(labels, y)
+	fmt.Printf("Silhouette: %v, Davies-Bouldin: %v, Calinski-Harabasz: %v, ARI: %v, NMI: %v\n",
+		silhouette, db, ch, ari, nmi)
+
+	assert.True(t, silhouette > 0.8)
+	assert.True(t, db < 0.3)
+	assert.InDelta(t, 1, ari, 1e-12)
+	assert.InDelta(t, 1, nmi, 1e-12)
+
+	//fewer clusters than the blobs
+	km2, _ := NewKMeans(2, 1)
+	labels2, _ := km2.FitPredict(x)
+	ch2, _ := CalinskiHarabaszScore(x, labels2)
+	assert.True(t, ch2 < ch)
+}
@@ -0,0 +1,221 @@
+package ml
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+)
+
+type (
+	//ROCCurve contains false and true positive rates for every distinct threshold
+	//thresholds are decreasing, the first point is (0, 0) with threshold +Inf
+	ROCCurve struct {
+		FPR        *Vector
+		TPR        *Vector
+		Thresholds *Vector
+	}
+
+	//PRCurve contains precision and recall for every distinct threshold
+	//thresholds are decreasing, the first point is recall 0 and precision 1 with threshold +Inf
+	PRCurve struct {
+		Precision  *Vector
+		Recall     *Vector
+		Thresholds *Vector
+	}
+)
+
+//count the true and false positives when everything with score >= threshold is predicted as 1
+//for every distinct score in decreasing order
+//equal scores are handled as a single threshold so ties don't depend on the order of the input
+func binaryClassifierCounts(scores, actual *Vector) (tps, fps, thresholds []float64, err error) {
+	if err := validateMetricInput(scores, actual); err != nil {
+		return nil, nil, nil, err
+	}
+
+	idx := indexList(actual.GetLength(), nil)
+	for _, i := range idx {
+		if y := actual.getSingleValue(i); y != 0 && y != 1 {
+			return nil, nil, nil, fmt.Errorf("Value of y should be either 0 or 1")
+		}
+	}
+
+	sort.SliceStable(idx, func(a, b int) bool {
+		return scores.getSingleValue(idx[a]) > scores.getSingleValue(idx[b])
+	})
+
+	var tp, fp float64
+	for key, i := range idx {
+		if actual.getSingleValue(i) == 1 {
+			tp++
+		} else {
+			fp++
+		}
+
+		//only add a point after the last element of a group of equal scores
+		score := scores.getSingleValue(i)
+		if key+1 < len(idx) && scores.getSingleValue(idx[key+1]) == score {
+			continue
+		}
+
+		tps = append(tps, tp)
+		fps = append(fps, fp)
+		thresholds = append(thresholds, score)
+	}
+
+	return tps, fps, thresholds, nil
+}
+
+//create the ROC curve from predicted scores (e.g. probabilities) and actual 0/1 labels
+//both labels should occur at least once
+func NewROCCurve(scores, actual *Vector) (*ROCCurve, error) {
+	tps, fps, thresholds, err := binaryClassifierCounts(scores, actual)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, neg := tps[len(tps)-1], fps[len(fps)-1]
+	if pos == 0 || neg == 0 {
+		return nil, fmt.Errorf("ROC curve needs both positive and negative samples")
+	}
+
+	fpr, tpr, thr := NewVector([]float64{0}), NewVector([]float64{0}), NewVector([]float64{math.Inf(1)})
+	for key := range tps {
+		fpr.AddValue(fps[key] / neg)
+		tpr.AddValue(tps[key] / pos)
+		thr.AddValue(thresholds[key])
+	}
+
+	return &ROCCurve{FPR: fpr, TPR: tpr, Thresholds: thr}, nil
+}
+
+//area under a curve using the trapezoidal rule
+func trapezoid(x, y *Vector) float64 {
+	var result float64
+	for i := 2; i <= x.GetLength(); i++ {
+		dx := x.getSingleValue(i) - x.getSingleValue(i-1)
+		result += dx * (y.getSingleValue(i) + y.getSingleValue(i-1)) / 2
+	}
+	return result
+}
+
+//get the area under the ROC curve
+func (c *ROCCurve) AUC() float64 {
+	return trapezoid(c.FPR, c.TPR)
+}
+
+//ROC AUC is the probability that a random positive sample gets
+//a higher score than a random negative one
+func ROCAUCScore(scores, actual *Vector) (float64, error) {
+	c, err := NewROCCurve(scores, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.AUC(), nil
+}
+
+//create the precision-recall curve from predicted scores and actual 0/1 labels
+//there should be at least one positive label
+func NewPRCurve(scores, actual *Vector) (*PRCurve, error) {
+	tps, fps, thresholds, err := binaryClassifierCounts(scores, actual)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := tps[len(tps)-1]
+	if pos == 0 {
+		return nil, fmt.Errorf("Precision-recall curve needs positive samples")
+	}
+
+	precision, recall, thr := NewVector([]float64{1}), NewVector([]float64{0}), NewVector([]float64{math.Inf(1)})
+	for key := range tps {
+		precision.AddValue(tps[key] / (tps[key] + fps[key]))
+		recall.AddValue(tps[key] / pos)
+		thr.AddValue(thresholds[key])
+	}
+
+	return &PRCurve{Precision: precision, Recall: recall, Thresholds: thr}, nil
+}
+
+//average precision is sigma(Rn - Rn-1) * Pn over all thresholds
+//it's a step function and doesn't interpolate between the points
+func (c *PRCurve) AveragePrecision() float64 {
+	var result float64
+	for i := 2; i <= c.Recall.GetLength(); i++ {
+		dr := c.Recall.getSingleValue(i) - c.Recall.getSingleValue(i-1)
+		result += dr * c.Precision.getSingleValue(i)
+	}
+	return result
+}
+
+func AveragePrecisionScore(scores, actual *Vector) (float64, error) {
+	c, err := NewPRCurve(scores, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.AveragePrecision(), nil
+}
+
+///////////////////////////
+////////EXPORT////////////
+//////////////////////////
+
+//write a header and the given columns as csv
+//all columns should have the same length
+func writeCurveCSV(w io.Writer, header []string, cols ...*Vector) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for i := 1; i <= cols[0].GetLength(); i++ {
+		var rec []string
+		for _, col := range cols {
+			rec = append(rec, strconv.FormatFloat(col.getSingleValue(i), 'g', -1, 64))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+//save the output of write into a new file
+func saveCSV(fileName string, write func(io.Writer) error) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+//write the curve as csv with the columns threshold, fpr and tpr
+func (c *ROCCurve) WriteCSV(w io.Writer) error {
+	return writeCurveCSV(w, []string{"threshold", "fpr", "tpr"}, c.Thresholds, c.FPR, c.TPR)
+}
+
+func (c *ROCCurve) SaveCSV(fileName string) error {
+	return saveCSV(fileName, c.WriteCSV)
+}
+
+//write the curve as csv with the columns threshold, precision and recall
+func (c *PRCurve) WriteCSV(w io.Writer) error {
+	return writeCurveCSV(w, []string{"threshold", "precision", "recall"}, c.Thresholds, c.Precision, c.Recall)
+}
+
+func (c *PRCurve) SaveCSV(fileName string) error {
+	return saveCSV(fileName, c.WriteCSV)
+}
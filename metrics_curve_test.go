@@ -0,0 +1,101 @@
+package ml
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestROCCurve(t *testing.T) {
+	actual := NewVector([]float64{0, 0, 1, 1})
+	scores := NewVector([]float64{0.1, 0.4, 0.35, 0.8})
+
+	_, err := NewROCCurve(scores, NewVector([]float64{0, 0, 1}))
+	assert.Error(t, err)
+
+	_, err = NewROCCurve(scores, NewVector([]float64{0, 0, 0, 0}))
+	assert.Error(t, err)
+
+	_, err = NewROCCurve(scores, NewVector([]float64{0, 0, 1, 2}))
+	assert.Error(t, err)
+
+	c, err := NewROCCurve(scores, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 0, 0.5, 0.5, 1}, c.FPR.val)
+	assert.Equal(t, []float64{0, 0.5, 0.5, 1, 1}, c.TPR.val)
+	assert.Equal(t, []float64{math.Inf(1), 0.8, 0.4, 0.35, 0.1}, c.Thresholds.val)
+
+	auc, err := ROCAUCScore(scores, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, auc)
+}
+
+func TestROCCurveWithTies(t *testing.T) {
+	//a single threshold for equal scores, so half a positive/negative pair counts as 0.5
+	actual := NewVector([]float64{1, 0, 1, 0})
+	scores := NewVector([]float64{0.5, 0.5, 0.9, 0.1})
+
+	c, err := NewROCCurve(scores, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, c.FPR.GetLength())
+	assert.Equal(t, 0.875, c.AUC())
+
+	//the order of the tied elements should not matter
+	actual2 := NewVector([]float64{0, 1, 1, 0})
+	auc, err := ROCAUCScore(scores, actual2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.875, auc)
+}
+
+func TestPRCurve(t *testing.T) {
+	actual := NewVector([]float64{0, 0, 1, 1})
+	scores := NewVector([]float64{0.1, 0.4, 0.35, 0.8})
+
+	_, err := NewPRCurve(scores, NewZeroVector(4))
+	assert.Error(t, err)
+
+	c, err := NewPRCurve(scores, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 0.5, 0.5, 1, 1}, c.Recall.val)
+	assert.Equal(t, "[1.00 , 1.00 , 0.50 , 0.67 , 0.50]", c.Precision.String())
+
+	ap, err := AveragePrecisionScore(scores, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.83333", fmt.Sprintf("%.5f", ap))
+}
+
+func TestCurveCSV(t *testing.T) {
+	actual := NewVector([]float64{0, 0, 1, 1})
+	scores := NewVector([]float64{0.1, 0.4, 0.35, 0.8})
+
+	roc, _ := NewROCCurve(scores, actual)
+	var buf bytes.Buffer
+	err := roc.WriteCSV(&buf)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 6, len(lines))
+	assert.Equal(t, "threshold,fpr,tpr", lines[0])
+	assert.Equal(t, "+Inf,0,0", lines[1])
+	assert.Equal(t, "0.8,0,0.5", lines[2])
+
+	dir, err := ioutil.TempDir("", "mlcurve")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pr, _ := NewPRCurve(scores, actual)
+	fileName := filepath.Join(dir, "pr.csv")
+	err = pr.SaveCSV(fileName)
+	assert.NoError(t, err)
+
+	file, err := ioutil.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(file), "threshold,precision,recall\n"))
+}
@@ -0,0 +1,185 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+//all regression metrics compare predicted against actual values
+//and can be used as ScoreFunc unless they need more parameters
+//as validation both vectors should have the same length and can't be empty
+func validateMetricInput(predicted, actual *Vector) error {
+	if predicted.GetLength() != actual.GetLength() {
+		return ErrVectorFalseDimension
+	}
+
+	if actual.GetLength() == 0 {
+		return ErrEmptyVector
+	}
+
+	return nil
+}
+
+//get the mean of all elements of a vector
+func mean(v *Vector) float64 {
+	var result float64
+	for _, val := range v.val {
+		result += val
+	}
+	return result / float64(v.GetLength())
+}
+
+//get the (population) variance of all elements of a vector
+func variance(v *Vector) float64 {
+	m := mean(v)
+
+	var result float64
+	for _, val := range v.val {
+		result += math.Pow(val-m, 2)
+	}
+	return result / float64(v.GetLength())
+}
+
+//get the difference between every predicted and actual value
+func residuals(predicted, actual *Vector) *Vector {
+	result := make([]float64, 0, actual.GetLength())
+	for i := 1; i <= actual.GetLength(); i++ {
+		result = append(result, actual.getSingleValue(i)-predicted.getSingleValue(i))
+	}
+	return NewVector(result)
+}
+
+//mean absolute error is 1/m * sigma(1...m)|yi - pi|
+func MeanAbsoluteError(predicted, actual *Vector) (float64, error) {
+	if err := validateMetricInput(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	res := residuals(predicted, actual)
+	res.Calculate(math.Abs)
+	return mean(res), nil
+}
+
+//mean squared error is 1/m * sigma(1...m)(yi - pi)^2
+func MeanSquaredError(predicted, actual *Vector) (float64, error) {
+	if err := validateMetricInput(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	res := residuals(predicted, actual)
+	res.PowerOf(2)
+	return mean(res), nil
+}
+
+//root mean squared error is the square root of the mean squared error
+//so it has the same unit as y
+func RootMeanSquaredError(predicted, actual *Vector) (float64, error) {
+	mse, err := MeanSquaredError(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Sqrt(mse), nil
+}
+
+//coefficient of determination R^2 is 1 - sigma(yi - pi)^2 / sigma(yi - mean(y))^2
+//1 is a perfect prediction, 0 is as good as always predicting the mean
+//if all actual values are the same, the result is 1 for a perfect prediction and 0 otherwise
+func R2Score(predicted, actual *Vector) (float64, error) {
+	if err := validateMetricInput(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	res := residuals(predicted, actual)
+	res.PowerOf(2)
+	ssRes := mean(res)
+	ssTot := variance(actual)
+
+	if ssTot == 0 {
+		if ssRes == 0 {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	return 1 - ssRes/ssTot, nil
+}
+
+//adjusted R^2 penalizes R^2 for the number of features used by the model
+//the formula is 1 - (1 - R^2) * (m - 1) / (m - numFeatures - 1)
+//m should be greater than numFeatures + 1
+func AdjustedR2Score(predicted, actual *Vector, numFeatures int) (float64, error) {
+	r2, err := R2Score(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	m := actual.GetLength()
+	if numFeatures < 0 || m-numFeatures-1 <= 0 {
+		return 0, fmt.Errorf("Number of samples(%d) should be greater than number of features(%d) + 1",
+			m, numFeatures)
+	}
+
+	return 1 - (1-r2)*float64(m-1)/float64(m-numFeatures-1), nil
+}
+
+//mean absolute percentage error is 1/m * sigma(1...m)|yi - pi| / |yi|
+//the result is a ratio e.g. 0.1 for 10%
+//actual values of 0 are replaced by the smallest positive float to avoid dividing by zero
+func MeanAbsolutePercentageError(predicted, actual *Vector) (float64, error) {
+	if err := validateMetricInput(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	res := residuals(predicted, actual)
+	res.CalculateVector(func(x float64, i int) float64 {
+		y := math.Max(math.Abs(actual.getSingleValue(i)), math.SmallestNonzeroFloat64)
+		return math.Abs(x) / y
+	})
+	return mean(res), nil
+}
+
+//median absolute error is the median of |yi - pi|
+//unlike the mean it's robust to outliers
+func MedianAbsoluteError(predicted, actual *Vector) (float64, error) {
+	if err := validateMetricInput(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	res := residuals(predicted, actual)
+	res.Calculate(math.Abs)
+	return median(res.val), nil
+}
+
+//get the median of a slice without changing its order
+func median(input []float64) float64 {
+	sorted := copyFloats(input)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+//explained variance is 1 - Var(y - p) / Var(y)
+//unlike R^2 it ignores a constant bias of the prediction
+func ExplainedVarianceScore(predicted, actual *Vector) (float64, error) {
+	if err := validateMetricInput(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	varRes := variance(residuals(predicted, actual))
+	varY := variance(actual)
+
+	if varY == 0 {
+		if varRes == 0 {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	return 1 - varRes/varY, nil
+}
@@ -0,0 +1,74 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegressionMetricsValidation(t *testing.T) {
+	v1 := NewVector([]float64{1, 2, 3})
+	v2 := NewVector([]float64{1, 2})
+
+	metrics := []ScoreFunc{MeanAbsoluteError, MeanSquaredError, RootMeanSquaredError,
+		R2Score, MeanAbsolutePercentageError, MedianAbsoluteError, ExplainedVarianceScore}
+
+	for _, metric := range metrics {
+		_, err := metric(v1, v2)
+		assert.Equal(t, ErrVectorFalseDimension, err)
+
+		_, err = metric(NewVector(nil), NewVector(nil))
+		assert.Equal(t, ErrEmptyVector, err)
+	}
+
+	_, err := AdjustedR2Score(v1, v2, 1)
+	assert.Equal(t, ErrVectorFalseDimension, err)
+}
+
+func TestRegressionMetrics(t *testing.T) {
+	actual := NewVector([]float64{3, -0.5, 2, 7})
+	predicted := NewVector([]float64{2.5, 0, 2, 8})
+
+	mae, err := MeanAbsoluteError(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, mae)
+
+	mse, err := MeanSquaredError(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.375, mse)
+
+	rmse, err := RootMeanSquaredError(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, math.Sqrt(0.375), rmse)
+
+	r2, err := R2Score(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.94861", fmt.Sprintf("%.5f", r2))
+
+	_, err = AdjustedR2Score(predicted, actual, 3)
+	assert.Error(t, err)
+
+	adj, err := AdjustedR2Score(predicted, actual, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.92291", fmt.Sprintf("%.5f", adj))
+
+	mape, err := MeanAbsolutePercentageError(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.32738", fmt.Sprintf("%.5f", mape))
+
+	medae, err := MedianAbsoluteError(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, medae)
+
+	ev, err := ExplainedVarianceScore(predicted, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.95717", fmt.Sprintf("%.5f", ev))
+
+	//perfect prediction of a constant
+	constant := NewConstantVector(3, 2)
+	r2, err = R2Score(constant, constant)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), r2)
+}
@@ -0,0 +1,366 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type (
+	//Params maps a hyperparameter name to its value
+	//integer parameters like the number of iterations are stored as float64 as well
+	Params map[string]float64
+
+	//EstimatorFactory creates a new untrained estimator with the given parameters
+	EstimatorFactory func(params Params) (Estimator, error)
+
+	//ParamGrid lists every value which should be tried for a parameter
+	ParamGrid map[string][]float64
+
+	//ParamDistribution draws random values of a parameter for RandomSearch
+	ParamDistribution interface {
+		Sample(rnd *rand.Rand) float64
+	}
+
+	//ParamSpace maps a parameter name to the distribution of its values
+	ParamSpace map[string]ParamDistribution
+
+	//UniformDistribution draws uniformly from [Low, High)
+	UniformDistribution struct {
+		Low, High float64
+	}
+
+	//LogUniformDistribution draws uniformly on a log scale from [Low, High)
+	//which suits parameters like alpha or lambda, both should be greater than 0
+	LogUniformDistribution struct {
+		Low, High float64
+	}
+
+	//IntUniformDistribution draws an integer from Low...High, both included
+	IntUniformDistribution struct {
+		Low, High int
+	}
+
+	//ChoiceDistribution draws one of its values with the same probability
+	ChoiceDistribution []float64
+
+	//GridSearch evaluates every combination of the parameter grid
+	//using cross validation and selects the best scoring one
+	//candidates are evaluated by Workers goroutines in parallel
+	GridSearch struct {
+		Factory         EstimatorFactory
+		Grid            ParamGrid
+		Splitter        Splitter
+		Score           ScoreFunc
+		GreaterIsBetter bool
+		Workers         int
+	}
+
+	//RandomSearch evaluates NumIter random candidates drawn from the parameter space
+	RandomSearch struct {
+		Factory         EstimatorFactory
+		Space           ParamSpace
+		NumIter         int
+		Seed            int64
+		Splitter        Splitter
+		Score           ScoreFunc
+		GreaterIsBetter bool
+		Workers         int
+	}
+
+	//SearchCandidate is the cross validation result of a single parameter set
+	//rank 1 is the best candidate
+	SearchCandidate struct {
+		Params Params
+		Result *CVResult
+		Rank   int
+	}
+
+	//SearchResult contains the best parameters and all evaluated candidates
+	//in the order they have been created
+	SearchResult struct {
+		BestParams Params
+		BestScore  float64
+		Candidates []*SearchCandidate
+	}
+)
+
+///////////////////////////
+////////DISTRIBUTION//////
+//////////////////////////
+
+func (d UniformDistribution) Sample(rnd *rand.Rand) float64 {
+	return d.Low + rnd.Float64()*(d.High-d.Low)
+}
+
+func (d LogUniformDistribution) Sample(rnd *rand.Rand) float64 {
+	low, high := math.Log(d.Low), math.Log(d.High)
+	return math.Exp(low + rnd.Float64()*(high-low))
+}
+
+func (d IntUniformDistribution) Sample(rnd *rand.Rand) float64 {
+	return float64(d.Low + rnd.Intn(d.High-d.Low+1))
+}
+
+func (d ChoiceDistribution) Sample(rnd *rand.Rand) float64 {
+	return d[rnd.Intn(len(d))]
+}
+
+//validate the distribution parameters so Sample can't panic
+func validateDistribution(name string, d ParamDistribution) error {
+	switch dist := d.(type) {
+	case UniformDistribution:
+		if dist.High < dist.Low {
+			return fmt.Errorf("Parameter %s: high should be greater than low", name)
+		}
+	case LogUniformDistribution:
+		if dist.Low <= 0 || dist.High < dist.Low {
+			return fmt.Errorf("Parameter %s: low should be greater than 0 and lesser than high", name)
+		}
+	case IntUniformDistribution:
+		if dist.High < dist.Low {
+			return fmt.Errorf("Parameter %s: high should be greater than low", name)
+		}
+	case ChoiceDistribution:
+		if len(dist) == 0 {
+			return fmt.Errorf("Parameter %s has no values", name)
+		}
+	case nil:
+		return fmt.Errorf("Parameter %s has no distribution", name)
+	}
+
+	return nil
+}
+
+///////////////////////////
+////////CANDIDATES////////
+//////////////////////////
+
+//get the keys of a parameter map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//create the cartesian product of all grid values
+//the order is deterministic, the last parameter (sorted by name) changes fastest
+func (g ParamGrid) candidates() ([]Params, error) {
+	names := sortedKeys(g)
+	for _, name := range names {
+		if len(g[name]) == 0 {
+			return nil, fmt.Errorf("Parameter %s has no values", name)
+		}
+	}
+
+	result := []Params{Params{}}
+	for _, name := range names {
+		var next []Params
+		for _, p := range result {
+			for _, val := range g[name] {
+				np := Params{name: val}
+				for k, v := range p {
+					np[k] = v
+				}
+				next = append(next, np)
+			}
+		}
+		result = next
+	}
+
+	return result, nil
+}
+
+//draw n random candidates from the parameter space
+func (s ParamSpace) candidates(n int, rnd *rand.Rand) ([]Params, error) {
+	names := sortedKeys(s)
+	for _, name := range names {
+		if err := validateDistribution(name, s[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []Params
+	for i := 0; i < n; i++ {
+		p := Params{}
+		for _, name := range names {
+			p[name] = s[name].Sample(rnd)
+		}
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+///////////////////////////
+////////SEARCH////////////
+//////////////////////////
+
+func NewGridSearch(factory EstimatorFactory, grid ParamGrid, splitter Splitter, score ScoreFunc, greaterIsBetter bool) (*GridSearch, error) {
+	if len(grid) == 0 {
+		return nil, fmt.Errorf("Parameter grid is empty")
+	}
+
+	return &GridSearch{
+		Factory:         factory,
+		Grid:            grid,
+		Splitter:        splitter,
+		Score:           score,
+		GreaterIsBetter: greaterIsBetter,
+		Workers:         1}, nil
+}
+
+func (gs *GridSearch) Fit(x *Matrix, y *Vector) (*SearchResult, error) {
+	candidates, err := gs.Grid.candidates()
+	if err != nil {
+		return nil, err
+	}
+
+	return search(gs.Factory, candidates, x, y, gs.Splitter, gs.Score, gs.GreaterIsBetter, gs.Workers)
+}
+
+func NewRandomSearch(factory EstimatorFactory, space ParamSpace, numIter int, seed int64, splitter Splitter, score ScoreFunc, greaterIsBetter bool) (*RandomSearch, error) {
+	if len(space) == 0 {
+		return nil, fmt.Errorf("Parameter space is empty")
+	}
+
+	if numIter < 1 {
+		return nil, fmt.Errorf("Number of iterations should be at least 1")
+	}
+
+	return &RandomSearch{
+		Factory:         factory,
+		Space:           space,
+		NumIter:         numIter,
+		Seed:            seed,
+		Splitter:        splitter,
+		Score:           score,
+		GreaterIsBetter: greaterIsBetter,
+		Workers:         1}, nil
+}
+
+func (rs *RandomSearch) Fit(x *Matrix, y *Vector) (*SearchResult, error) {
+	candidates, err := rs.Space.candidates(rs.NumIter, rand.New(rand.NewSource(rs.Seed)))
+	if err != nil {
+		return nil, err
+	}
+
+	return search(rs.Factory, candidates, x, y, rs.Splitter, rs.Score, rs.GreaterIsBetter, rs.Workers)
+}
+
+//cross validate a single candidate on the given folds
+func evaluateCandidate(factory EstimatorFactory, params Params, x *Matrix, y *Vector, folds []Fold, score ScoreFunc) (*CVResult, error) {
+	scores := NewZeroVector(len(folds))
+	for key, fold := range folds {
+		est, err := factory(params)
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := scoreFold(est, x, y, fold, score)
+		if err != nil {
+			return nil, fmt.Errorf("Fold %d: %s", key+1, err)
+		}
+		scores.setSingleValue(key+1, s)
+	}
+
+	return newCVResult(scores), nil
+}
+
+//evaluate all candidates on the same folds using a pool of workers
+//and rank them by their mean score
+func search(factory EstimatorFactory, candidates []Params, x *Matrix, y *Vector, splitter Splitter, score ScoreFunc, greaterIsBetter bool, workers int) (*SearchResult, error) {
+	folds, err := splitter.Split(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]*SearchCandidate, len(candidates))
+	errs := make([]error, len(candidates))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res, err := evaluateCandidate(factory, candidates[i], x, y, folds, score)
+				results[i], errs[i] = &SearchCandidate{Params: candidates[i], Result: res}, err
+			}
+		}()
+	}
+
+	for i := range candidates {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for key, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("Candidate %s: %s", candidates[key], err)
+		}
+	}
+
+	//rank the candidates, equal scores keep their original order
+	//a NaN score e.g. of a diverged model is ranked after every other score
+	order := indexList(len(results), nil)
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := results[order[a]-1].Result.Mean, results[order[b]-1].Result.Mean
+		switch {
+		case math.IsNaN(sa):
+			return false
+		case math.IsNaN(sb):
+			return true
+		case greaterIsBetter:
+			return sa > sb
+		}
+		return sa < sb
+	})
+	for rank, i := range order {
+		results[i-1].Rank = rank + 1
+	}
+
+	best := results[order[0]-1]
+	if math.IsNaN(best.Result.Mean) {
+		return nil, fmt.Errorf("Every candidate has a NaN score")
+	}
+	return &SearchResult{
+		BestParams: best.Params,
+		BestScore:  best.Result.Mean,
+		Candidates: results}, nil
+}
+
+func (p Params) String() string {
+	var list []string
+	for _, name := range sortedKeys(p) {
+		list = append(list, fmt.Sprintf("%s=%g", name, p[name]))
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(list, ", "))
+}
+
+//print all candidates as a table sorted by rank
+func (r *SearchResult) String() string {
+	sorted := append([]*SearchCandidate{}, r.Candidates...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Rank < sorted[b].Rank })
+
+	sprint := fmt.Sprintf("Best parameters: %s\nBest score: %.5f\n", r.BestParams, r.BestScore)
+	sprint += fmt.Sprintf("%5s %12s %12s  %s\n", "Rank", "Mean", "Std", "Parameters")
+	for _, c := range sorted {
+		sprint += fmt.Sprintf("%5d %12.5f %12.5f  %s\n", c.Rank, c.Result.Mean, c.Result.Std, c.Params)
+	}
+
+	return sprint
+}
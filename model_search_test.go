@@ -0,0 +1,156 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func linearRegressionFactory(params Params) (Estimator, error) {
+	return &LinearRegressionEstimator{
+		Alpha:      params["alpha"],
+		Lambda:     params["lambda"],
+		Iterations: int(params["iterations"])}, nil
+}
+
+func TestParamGridCandidates(t *testing.T) {
+	grid := ParamGrid{
+		"alpha":      []float64{0.01, 0.001},
+		"iterations": []float64{10, 20, 30},
+	}
+
+	candidates, err := grid.candidates()
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(candidates))
+	assert.Equal(t, Params{"alpha": 0.01, "iterations": 10}, candidates[0])
+	assert.Equal(t, Params{"alpha": 0.001, "iterations": 30}, candidates[5])
+
+	_, err = ParamGrid{"alpha": []float64{}}.candidates()
+	assert.Error(t, err)
+}
+
+func TestParamDistributions(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		u := UniformDistribution{Low: 1, High: 2}.Sample(rnd)
+		assert.True(t, u >= 1 && u < 2)
+
+		l := LogUniformDistribution{Low: 0.0001, High: 0.1}.Sample(rnd)
+		assert.True(t, l >= 0.0001 && l < 0.1)
+
+		n := IntUniformDistribution{Low: 3, High: 5}.Sample(rnd)
+		assert.Contains(t, []float64{3, 4, 5}, n)
+
+		c := ChoiceDistribution{0, 0.1}.Sample(rnd)
+		assert.Contains(t, []float64{0, 0.1}, c)
+	}
+
+	_, err := ParamSpace{"lambda": LogUniformDistribution{Low: 0, High: 1}}.candidates(1, rnd)
+	assert.Error(t, err)
+}
+
+func TestGridSearchLinearRegression(t *testing.T) {
+	file := "data3.csv"
+	x, _ := LoadNewMatrix(file, ":", "1")
+	y, _ := LoadNewVector(file, ":", "2")
+
+	kf, _ := NewKFold(3, true, 1)
+
+	_, err := NewGridSearch(linearRegressionFactory, ParamGrid{}, kf, MeanSquaredError, false)
+	assert.Error(t, err)
+
+	grid := ParamGrid{
+		"alpha":      []float64{0.01, 0.001},
+		"lambda":     []float64{0, 1},
+		"iterations": []float64{10, 200},
+	}
+	gs, err := NewGridSearch(linearRegressionFactory, grid, kf, MeanSquaredError, false)
+	assert.NoError(t, err)
+	gs.Workers = 4
+
+	res, err := gs.Fit(x, y)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, len(res.Candidates))
+	assert.Equal(t, 0.01, res.BestParams["alpha"])
+	assert.Equal(t, float64(200), res.BestParams["iterations"])
+	fmt.Println(res)
+
+	//parallel evaluation gives the same result as a single worker
+	gs.Workers = 1
+	res2, err := gs.Fit(x, y)
+	assert.NoError(t, err)
+	assert.Equal(t, res.BestParams, res2.BestParams)
+	for key, c := range res.Candidates {
+		assert.Equal(t, c.Result.Mean, res2.Candidates[key].Result.Mean)
+		assert.Equal(t, c.Rank, res2.Candidates[key].Rank)
+	}
+
+	//errors of the estimator are reported
+	grid["alpha"] = []float64{0}
+	_, err = gs.Fit(x, y)
+	assert.Error(t, err)
+}
+
+//alpha 100 diverges and gets a NaN score, which should be ranked last
+func TestGridSearchNaNScore(t *testing.T) {
+	x, y := loadData3(), loadData3Y()
+	kf, _ := NewKFold(3, false, 1)
+
+	for _, greaterIsBetter := range []bool{false, true} {
+		score := MeanSquaredError
+		if greaterIsBetter {
+			score = R2Score
+		}
+
+		grid := ParamGrid{"alpha": []float64{100, 0.01, 0.001}, "iterations": []float64{1000}}
+		gs, _ := NewGridSearch(linearRegressionFactory, grid, kf, score, greaterIsBetter)
+		res, err := gs.Fit(x, y)
+		assert.NoError(t, err)
+		assert.True(t, math.IsNaN(res.Candidates[0].Result.Mean))
+		assert.Equal(t, 3, res.Candidates[0].Rank)
+		assert.Equal(t, 0.01, res.BestParams["alpha"])
+		assert.False(t, math.IsNaN(res.BestScore))
+
+		//there is no best candidate if all of them diverge
+		grid["alpha"] = []float64{100, 10}
+		_, err = gs.Fit(x, y)
+		assert.Error(t, err)
+	}
+}
+
+func TestRandomSearchLogisticRegression(t *testing.T) {
+	file := "data1.csv"
+	x, _ := LoadNewMatrix(file, ":", "1:2")
+	y, _ := LoadNewVector(file, ":", "3")
+
+	factory := func(params Params) (Estimator, error) {
+		return &LogisticRegressionEstimator{
+			Alpha:      params["alpha"],
+			Iterations: int(params["iterations"])}, nil
+	}
+
+	space := ParamSpace{
+		"alpha":      LogUniformDistribution{Low: 0.0001, High: 0.01},
+		"iterations": IntUniformDistribution{Low: 50, High: 300},
+	}
+
+	skf, _ := NewStratifiedKFold(3, true, 1)
+
+	_, err := NewRandomSearch(factory, space, 0, 1, skf, Accuracy, true)
+	assert.Error(t, err)
+
+	rs, err := NewRandomSearch(factory, space, 5, 1, skf, Accuracy, true)
+	assert.NoError(t, err)
+	rs.Workers = 2
+
+	res, err := rs.Fit(x, y)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(res.Candidates))
+	for _, c := range res.Candidates {
+		assert.True(t, c.Result.Mean <= res.BestScore)
+	}
+	fmt.Println(res)
+}
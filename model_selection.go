@@ -0,0 +1,333 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+type (
+	//Estimator is a model which can be trained and then used for prediction
+	//Fit should not modify x and y
+	Estimator interface {
+		Fit(x *Matrix, y *Vector) error
+		Predict(x *Matrix) (*Vector, error)
+	}
+
+	//ScoreFunc compares predicted against actual values and returns a single score
+	ScoreFunc func(predicted, actual *Vector) (float64, error)
+
+	//Fold is one train/test partition of a data set
+	//both contain 1-based row indices
+	Fold struct {
+		Train []int
+		Test  []int
+	}
+
+	//Splitter partitions the rows of a data set into folds for cross validation
+	Splitter interface {
+		Split(x *Matrix, y *Vector) ([]Fold, error)
+	}
+
+	//KFold splits the data into K consecutive folds
+	//every fold is used exactly once as test set
+	KFold struct {
+		K       int
+		Shuffle bool
+		Seed    int64
+	}
+
+	//StratifiedKFold works like KFold but keeps the share of every label
+	//in each fold as close as possible to the share in the whole data set
+	StratifiedKFold struct {
+		K       int
+		Shuffle bool
+		Seed    int64
+	}
+
+	//CVResult is the outcome of CrossValidate
+	CVResult struct {
+		Scores *Vector
+		Mean   float64
+		Std    float64
+	}
+)
+
+///////////////////////////
+////////TRAIN/TEST////////
+//////////////////////////
+
+//validate the inputs of every splitting function
+func validateSplitInput(x *Matrix, y *Vector) error {
+	if err := x.validate(); err != nil {
+		return err
+	}
+
+	if x.GetRowNumber() != y.GetLength() {
+		return fmt.Errorf("X and Y row number are not the same")
+	}
+
+	return nil
+}
+
+//group the indices by their label
+//the groups are sorted by label so the result doesn't depend on map order
+func groupByLabel(idx []int, y *Vector) [][]int {
+	groups := map[float64][]int{}
+	for _, i := range idx {
+		label := y.getSingleValue(i)
+		groups[label] = append(groups[label], i)
+	}
+
+	var labels []float64
+	for label := range groups {
+		labels = append(labels, label)
+	}
+	sort.Float64s(labels)
+
+	var result [][]int
+	for _, label := range labels {
+		result = append(result, groups[label])
+	}
+	return result
+}
+
+//create the list of 1-based indices 1...n, shuffled if rnd is not nil
+func indexList(n int, rnd *rand.Rand) []int {
+	result := make([]int, n)
+	for i := range result {
+		result[i] = i + 1
+	}
+
+	if rnd != nil {
+		rnd.Shuffle(n, func(i, j int) { result[i], result[j] = result[j], result[i] })
+	}
+
+	return result
+}
+
+//split the rows of x and y into a train and a test set
+//ratio is the share of rows used for training, e.g. 0.8
+func trainTestSplit(x *Matrix, y *Vector, ratio float64, rnd *rand.Rand, stratify bool) (*Matrix, *Matrix, *Vector, *Vector, error) {
+	if err := validateSplitInput(x, y); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	if ratio <= 0 || ratio >= 1 {
+		return nil, nil, nil, nil, fmt.Errorf("Ratio should be between 0 and 1")
+	}
+
+	idx := indexList(x.GetRowNumber(), rnd)
+
+	var train, test []int
+	if stratify {
+		//take the same share from every label
+		for _, group := range groupByLabel(idx, y) {
+			numTrain := int(math.Round(ratio * float64(len(group))))
+			train = append(train, group[:numTrain]...)
+			test = append(test, group[numTrain:]...)
+		}
+	} else {
+		numTrain := int(math.Round(ratio * float64(len(idx))))
+		train, test = idx[:numTrain], idx[numTrain:]
+	}
+
+	if len(train) == 0 || len(test) == 0 {
+		return nil, nil, nil, nil, fmt.Errorf("Ratio %.2f leaves either train or test set empty", ratio)
+	}
+
+	xTrain, xTest := x.selectValue(train, nil, false), x.selectValue(test, nil, false)
+	yTrain, yTest := y.selectIndex(train), y.selectIndex(test)
+	return xTrain, xTest, yTrain, yTest, nil
+}
+
+//shuffle the rows using seed and split them into a train and a test set
+//ratio is the share of rows used for training, e.g. 0.8
+func TrainTestSplit(x *Matrix, y *Vector, ratio float64, seed int64) (*Matrix, *Matrix, *Vector, *Vector, error) {
+	return trainTestSplit(x, y, ratio, rand.New(rand.NewSource(seed)), false)
+}
+
+//same as TrainTestSplit but both sets keep the share of every label of y
+func StratifiedTrainTestSplit(x *Matrix, y *Vector, ratio float64, seed int64) (*Matrix, *Matrix, *Vector, *Vector, error) {
+	return trainTestSplit(x, y, ratio, rand.New(rand.NewSource(seed)), true)
+}
+
+//split the rows without shuffling them
+//e.g. ratio 0.8 on 100 rows is the same as loading rows "1:80" and "81:100"
+func SequentialTrainTestSplit(x *Matrix, y *Vector, ratio float64) (*Matrix, *Matrix, *Vector, *Vector, error) {
+	return trainTestSplit(x, y, ratio, nil, false)
+}
+
+///////////////////////////
+////////K-FOLD////////////
+//////////////////////////
+
+//create a new k-fold splitter, k should be at least 2
+func NewKFold(k int, shuffle bool, seed int64) (*KFold, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("Number of folds should be at least 2")
+	}
+
+	return &KFold{K: k, Shuffle: shuffle, Seed: seed}, nil
+}
+
+//create folds out of test sets, the train set of a fold is everything else
+func foldsFromTestSets(tests [][]int, idx []int) []Fold {
+	var result []Fold
+	for _, test := range tests {
+		inTest := map[int]bool{}
+		for _, i := range test {
+			inTest[i] = true
+		}
+
+		var train []int
+		for _, i := range idx {
+			if !inTest[i] {
+				train = append(train, i)
+			}
+		}
+
+		result = append(result, Fold{Train: train, Test: test})
+	}
+	return result
+}
+
+func (kf *KFold) Split(x *Matrix, y *Vector) ([]Fold, error) {
+	if err := validateSplitInput(x, y); err != nil {
+		return nil, err
+	}
+
+	n := x.GetRowNumber()
+	if kf.K < 2 || kf.K > n {
+		return nil, fmt.Errorf("Number of folds should be between 2 and number of rows")
+	}
+
+	var rnd *rand.Rand
+	if kf.Shuffle {
+		rnd = rand.New(rand.NewSource(kf.Seed))
+	}
+	idx := indexList(n, rnd)
+
+	//the first n % k folds get one more element
+	var tests [][]int
+	start := 0
+	for i := 0; i < kf.K; i++ {
+		size := n / kf.K
+		if i < n%kf.K {
+			size++
+		}
+		tests = append(tests, idx[start:start+size])
+		start += size
+	}
+
+	return foldsFromTestSets(tests, idx), nil
+}
+
+//create a new stratified k-fold splitter, k should be at least 2
+func NewStratifiedKFold(k int, shuffle bool, seed int64) (*StratifiedKFold, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("Number of folds should be at least 2")
+	}
+
+	return &StratifiedKFold{K: k, Shuffle: shuffle, Seed: seed}, nil
+}
+
+func (kf *StratifiedKFold) Split(x *Matrix, y *Vector) ([]Fold, error) {
+	if err := validateSplitInput(x, y); err != nil {
+		return nil, err
+	}
+
+	n := x.GetRowNumber()
+	if kf.K < 2 || kf.K > n {
+		return nil, fmt.Errorf("Number of folds should be between 2 and number of rows")
+	}
+
+	var rnd *rand.Rand
+	if kf.Shuffle {
+		rnd = rand.New(rand.NewSource(kf.Seed))
+	}
+	idx := indexList(n, rnd)
+
+	//deal the rows of every label to the folds one after another
+	//the dealing continues across labels so the fold sizes stay balanced
+	tests := make([][]int, kf.K)
+	next := 0
+	for _, group := range groupByLabel(idx, y) {
+		for _, i := range group {
+			tests[next] = append(tests[next], i)
+			next = (next + 1) % kf.K
+		}
+	}
+
+	return foldsFromTestSets(tests, idx), nil
+}
+
+///////////////////////////
+////////VALIDATION////////
+//////////////////////////
+
+//train a new estimator from factory on the train set of every fold
+//and score its prediction on the test set
+func CrossValidate(factory func() Estimator, x *Matrix, y *Vector, splitter Splitter, score ScoreFunc) (*CVResult, error) {
+	folds, err := splitter.Split(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := NewZeroVector(len(folds))
+	for key, fold := range folds {
+		s, err := scoreFold(factory(), x, y, fold, score)
+		if err != nil {
+			return nil, fmt.Errorf("Fold %d: %s", key+1, err)
+		}
+		scores.setSingleValue(key+1, s)
+	}
+
+	return newCVResult(scores), nil
+}
+
+//fit the estimator on the train set of a fold and score it on the test set
+func scoreFold(est Estimator, x *Matrix, y *Vector, fold Fold, score ScoreFunc) (float64, error) {
+	if err := est.Fit(x.selectValue(fold.Train, nil, false), y.selectIndex(fold.Train)); err != nil {
+		return 0, err
+	}
+
+	pred, err := est.Predict(x.selectValue(fold.Test, nil, false))
+	if err != nil {
+		return 0, err
+	}
+
+	return score(pred, y.selectIndex(fold.Test))
+}
+
+func newCVResult(scores *Vector) *CVResult {
+	n := float64(scores.GetLength())
+
+	var mean float64
+	for _, s := range scores.val {
+		mean += s
+	}
+	mean = mean / n
+
+	var variance float64
+	for _, s := range scores.val {
+		variance += math.Pow(s-mean, 2)
+	}
+
+	return &CVResult{
+		Scores: scores,
+		Mean:   mean,
+		Std:    math.Sqrt(variance / n)}
+}
+
+func (r *CVResult) String() string {
+	var lines []string
+	for key, s := range r.Scores.val {
+		lines = append(lines, fmt.Sprintf("Fold %d: %.5f", key+1, s))
+	}
+	lines = append(lines, fmt.Sprintf("Mean: %.5f (+/- %.5f)", r.Mean, r.Std))
+
+	return strings.Join(lines, "\n") + "\n"
+}
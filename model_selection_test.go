@@ -0,0 +1,158 @@
+package ml
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSplitData() (*Matrix, *Vector) {
+	var input [][]float64
+	var labels []float64
+	for i := 1; i <= 10; i++ {
+		input = append(input, []float64{float64(i), float64(i * 10)})
+		if i <= 4 {
+			labels = append(labels, 1)
+		} else {
+			labels = append(labels, 0)
+		}
+	}
+
+	x, _ := NewMatrix(input)
+	return x, NewVector(labels)
+}
+
+func countLabel(v *Vector, label float64) int {
+	var count int
+	for _, val := range v.val {
+		if val == label {
+			count++
+		}
+	}
+	return count
+}
+
+func TestTrainTestSplit(t *testing.T) {
+	x, y := newSplitData()
+
+	_, _, _, _, err := TrainTestSplit(x, y, 1, 1)
+	assert.Error(t, err)
+
+	_, _, _, _, err = TrainTestSplit(x, NewZeroVector(3), 0.8, 1)
+	assert.Error(t, err)
+
+	xTrain, xTest, yTrain, yTest, err := TrainTestSplit(x, y, 0.8, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, xTrain.GetRowNumber())
+	assert.Equal(t, 2, xTest.GetRowNumber())
+	assert.Equal(t, 8, yTrain.GetLength())
+	assert.Equal(t, 2, yTest.GetLength())
+
+	//rows and labels should still belong together
+	for i := 1; i <= xTest.GetRowNumber(); i++ {
+		id := xTest.getSingleValue(i, 1)
+		assert.Equal(t, id*10, xTest.getSingleValue(i, 2))
+		assert.Equal(t, y.getSingleValue(int(id)), yTest.getSingleValue(i))
+	}
+
+	//same seed gives the same split
+	_, xTest2, _, _, _ := TrainTestSplit(x, y, 0.8, 1)
+	assert.Equal(t, xTest, xTest2)
+
+	xTrain, xTest, _, _, err = SequentialTrainTestSplit(x, y, 0.8)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), xTrain.getSingleValue(1, 1))
+	assert.Equal(t, float64(9), xTest.getSingleValue(1, 1))
+
+	_, _, yTrain, yTest, err = StratifiedTrainTestSplit(x, y, 0.5, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countLabel(yTrain, 1))
+	assert.Equal(t, 3, countLabel(yTrain, 0))
+	assert.Equal(t, 2, countLabel(yTest, 1))
+	assert.Equal(t, 3, countLabel(yTest, 0))
+}
+
+func TestKFold(t *testing.T) {
+	x, y := newSplitData()
+
+	_, err := NewKFold(1, false, 0)
+	assert.Error(t, err)
+
+	kf, err := NewKFold(3, true, 1)
+	assert.NoError(t, err)
+
+	folds, err := kf.Split(x, y)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(folds))
+	assert.Equal(t, 4, len(folds[0].Test))
+	assert.Equal(t, 3, len(folds[2].Test))
+
+	//every row is tested exactly once
+	var tested []int
+	for _, fold := range folds {
+		assert.Equal(t, 10, len(fold.Train)+len(fold.Test))
+		tested = append(tested, fold.Test...)
+	}
+	sort.Ints(tested)
+	assert.Equal(t, indexList(10, nil), tested)
+
+	_, err = (&KFold{K: 11}).Split(x, y)
+	assert.Error(t, err)
+}
+
+func TestStratifiedKFold(t *testing.T) {
+	x, y := newSplitData()
+
+	skf, err := NewStratifiedKFold(2, true, 1)
+	assert.NoError(t, err)
+
+	folds, err := skf.Split(x, y)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(folds))
+	for _, fold := range folds {
+		yTest := y.selectIndex(fold.Test)
+		assert.Equal(t, 2, countLabel(yTest, 1))
+		assert.Equal(t, 3, countLabel(yTest, 0))
+	}
+}
+
+func TestCrossValidateLinearRegression(t *testing.T) {
+	file := "data3.csv"
+	x, _ := LoadNewMatrix(file, ":", "1")
+	y, _ := LoadNewVector(file, ":", "2")
+
+	factory := func() Estimator {
+		return &LinearRegressionEstimator{Alpha: 0.01, Iterations: 500}
+	}
+
+	kf, _ := NewKFold(5, true, 42)
+	res, err := CrossValidate(factory, x, y, kf, MeanAbsoluteError)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, res.Scores.GetLength())
+	assert.True(t, res.Mean > 0)
+	fmt.Printf("Linear regression 5-fold mean absolute error:\n%s\n", res)
+
+	//the input should not be modified by training
+	assert.Equal(t, 1, x.GetColumnNumber())
+}
+
+func TestCrossValidateLogisticRegression(t *testing.T) {
+	file := "data1.csv"
+	x, _ := LoadNewMatrix(file, ":", "1:2")
+	y, _ := LoadNewVector(file, ":", "3")
+
+	factory := func() Estimator {
+		return &LogisticRegressionEstimator{Alpha: 0.001, Iterations: 500}
+	}
+
+	skf, _ := NewStratifiedKFold(4, true, 42)
+	res, err := CrossValidate(factory, x, y, skf, Accuracy)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, res.Scores.GetLength())
+	fmt.Printf("Logistic regression 4-fold accuracy:\n%s\n", res)
+
+	_, err = (&LogisticRegressionEstimator{}).Predict(x)
+	assert.Equal(t, ErrNotFitted, err)
+}
@@ -0,0 +1,539 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+type (
+	//nbBase keeps the classes and the number of rows seen per class
+	//it is shared by all naive bayes models
+	nbBase struct {
+		classes     []float64
+		counts      map[float64]float64
+		numFeatures int
+	}
+
+	//gaussianStats holds the running mean and sum of squared deviations per column of one class
+	gaussianStats struct {
+		mean, m2 []float64
+	}
+
+	//GaussianNB assumes every column is normally distributed within a class
+	//VarSmoothing times the greatest column variance is added to every variance for stability
+	GaussianNB struct {
+		VarSmoothing float64
+
+		nbBase
+		stats map[float64]*gaussianStats
+	}
+
+	//MultinomialNB models non-negative counts like word counts of a text
+	//Alpha is the additive (laplace) smoothing of the counts
+	MultinomialNB struct {
+		Alpha float64
+
+		nbBase
+		featureCounts map[float64][]float64
+	}
+
+	//BernoulliNB models binary features, values greater than Binarize count as 1
+	//Alpha is the additive (laplace) smoothing of the counts
+	BernoulliNB struct {
+		Alpha    float64
+		Binarize float64
+
+		nbBase
+		featureCounts map[float64][]float64
+	}
+)
+
+func NewGaussianNB() *GaussianNB {
+	return &GaussianNB{VarSmoothing: 1e-9}
+}
+
+//alpha should be greater than 0, 1 is the laplace smoothing
+func NewMultinomialNB(alpha float64) (*MultinomialNB, error) {
+	if err := validateAlpha(alpha); err != nil {
+		return nil, err
+	}
+	return &MultinomialNB{Alpha: alpha}, nil
+}
+
+//alpha should be greater than 0, 1 is the laplace smoothing
+func NewBernoulliNB(alpha, binarize float64) (*BernoulliNB, error) {
+	if err := validateAlpha(alpha); err != nil {
+		return nil, err
+	}
+	return &BernoulliNB{Alpha: alpha, Binarize: binarize}, nil
+}
+
+func validateAlpha(alpha float64) error {
+	if alpha <= 0 {
+		return fmt.Errorf("Smoothing alpha should be greater than 0")
+	}
+	return nil
+}
+
+///////////////////////////
+////////BASE//////////////
+//////////////////////////
+
+func (nb *nbBase) reset() {
+	nb.classes, nb.counts, nb.numFeatures = nil, nil, 0
+}
+
+//x should be valid with one row per label
+//the number of columns should be the same for every batch
+func (nb *nbBase) validateInput(x *Matrix, y *Vector) error {
+	if err := x.validate(); err != nil {
+		return err
+	}
+
+	if x.GetRowNumber() != y.GetLength() {
+		return fmt.Errorf("X and Y row number are not the same")
+	}
+
+	if nb.numFeatures != 0 && x.GetColumnNumber() != nb.numFeatures {
+		return fmt.Errorf("Input dimension(%d) does not agree with the previous batches(%d)",
+			x.GetColumnNumber(), nb.numFeatures)
+	}
+
+	return nil
+}
+
+//count one more row of label and return the new count
+func (nb *nbBase) addRow(label float64) float64 {
+	if nb.counts == nil {
+		nb.counts = make(map[float64]float64)
+	}
+
+	nb.counts[label]++
+	return nb.counts[label]
+}
+
+//sort the classes after a batch, new classes are added in sorted order
+func (nb *nbBase) updateClasses(numFeatures int) {
+	nb.numFeatures = numFeatures
+
+	var labels []float64
+	for label := range nb.counts {
+		labels = append(labels, label)
+	}
+	nb.classes = uniqueSorted(labels)
+}
+
+//get the sorted labels seen so far
+func (nb *nbBase) Classes() []float64 { return copyFloats(nb.classes) }
+
+//log of the share of rows of every class
+func (nb *nbBase) logPriors() []float64 {
+	var total float64
+	for _, c := range nb.counts {
+		total += c
+	}
+
+	result := make([]float64, len(nb.classes))
+	for i, class := range nb.classes {
+		result[i] = math.Log(nb.counts[class] / total)
+	}
+	return result
+}
+
+//normalize the joint log likelihood of every row of x into log probabilities
+//column j of the result belongs to class j
+func (nb *nbBase) logProba(x *Matrix, jll func(row []float64) []float64) (*Matrix, error) {
+	if nb.classes == nil {
+		return nil, ErrNotFitted
+	}
+
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+
+	if x.GetColumnNumber() != nb.numFeatures {
+		return nil, fmt.Errorf("Input dimension(%d) does not agree with training data(%d)",
+			x.GetColumnNumber(), nb.numFeatures)
+	}
+
+	rows := x.rowSlices()
+	input := make([][]float64, len(rows))
+	parallelRows(len(rows), len(nb.classes)*nb.numFeatures, func(from, to int) {
+		for i := from; i < to; i++ {
+			l := jll(rows[i])
+			norm := logSumExp(l)
+			for j := range l {
+				l[j] -= norm
+			}
+			input[i] = l
+		}
+	})
+
+	return NewMatrix(input)
+}
+
+//log(sigma exp(vi)) without overflow
+func logSumExp(val []float64) float64 {
+	max := math.Inf(-1)
+	for _, f := range val {
+		max = math.Max(max, f)
+	}
+	if math.IsInf(max, -1) {
+		return max
+	}
+
+	var sum float64
+	for _, f := range val {
+		sum += math.Exp(f - max)
+	}
+	return max + math.Log(sum)
+}
+
+func (nb *nbBase) proba(x *Matrix, jll func(row []float64) []float64) (*Matrix, error) {
+	result, err := nb.logProba(x, jll)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Exp(), nil
+}
+
+//predict the class with the greatest probability
+func (nb *nbBase) predict(x *Matrix, jll func(row []float64) []float64) (*Vector, error) {
+	logProba, err := nb.logProba(x, jll)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewZeroVector(logProba.GetRowNumber())
+	for i := 1; i <= logProba.GetRowNumber(); i++ {
+		best := 0
+		for j, p := range logProba.val[i].val {
+			if p > logProba.val[i].val[best] {
+				best = j
+			}
+		}
+		result.val[i-1] = nb.classes[best]
+	}
+	return result, nil
+}
+
+///////////////////////////
+////////GAUSSIAN//////////
+//////////////////////////
+
+//train from scratch on x and y
+func (nb *GaussianNB) Fit(x *Matrix, y *Vector) error {
+	nb.reset()
+	nb.stats = nil
+	return nb.PartialFit(x, y)
+}
+
+//update the class statistics with a new batch
+//means and variances are updated row by row with welford's algorithm
+//so the result is the same as training on all batches at once
+func (nb *GaussianNB) PartialFit(x *Matrix, y *Vector) error {
+	if nb.VarSmoothing < 0 {
+		return fmt.Errorf("VarSmoothing should not be negative")
+	}
+
+	if err := nb.validateInput(x, y); err != nil {
+		return err
+	}
+
+	if nb.stats == nil {
+		nb.stats = make(map[float64]*gaussianStats)
+	}
+
+	for i, row := range x.rowSlices() {
+		label := y.val[i]
+		s, ok := nb.stats[label]
+		if !ok {
+			s = &gaussianStats{mean: make([]float64, len(row)), m2: make([]float64, len(row))}
+			nb.stats[label] = s
+		}
+
+		n := nb.addRow(label)
+		for j, f := range row {
+			delta := f - s.mean[j]
+			s.mean[j] += delta / n
+			s.m2[j] += delta * (f - s.mean[j])
+		}
+	}
+
+	nb.updateClasses(x.GetColumnNumber())
+	return nil
+}
+
+//get the smoothed variances of every class, row i belongs to Classes()[i-1]
+func (nb *GaussianNB) variances() [][]float64 {
+	result := make([][]float64, len(nb.classes))
+	var max float64
+	for i, class := range nb.classes {
+		result[i] = make([]float64, nb.numFeatures)
+		for j, m2 := range nb.stats[class].m2 {
+			result[i][j] = m2 / nb.counts[class]
+			max = math.Max(max, result[i][j])
+		}
+	}
+
+	//columns without variance would divide by 0
+	epsilon := nb.VarSmoothing * max
+	if epsilon == 0 {
+		epsilon = 1e-9
+	}
+	for _, row := range result {
+		for j := range row {
+			row[j] += epsilon
+		}
+	}
+	return result
+}
+
+//log prior + sigma(1...n) log N(xj | mean, variance) for every class
+func (nb *GaussianNB) jointLogLikelihood() func(row []float64) []float64 {
+	priors := nb.logPriors()
+	variances := nb.variances()
+
+	return func(row []float64) []float64 {
+		result := make([]float64, len(nb.classes))
+		for i, class := range nb.classes {
+			result[i] = priors[i]
+			for j, f := range row {
+				v := variances[i][j]
+				d := f - nb.stats[class].mean[j]
+				result[i] -= 0.5 * (math.Log(2*math.Pi*v) + d*d/v)
+			}
+		}
+		return result
+	}
+}
+
+//get the mean of every column per class, row i belongs to Classes()[i-1]
+func (nb *GaussianNB) Means() (*Matrix, error) {
+	if nb.classes == nil {
+		return nil, ErrNotFitted
+	}
+
+	input := make([][]float64, len(nb.classes))
+	for i, class := range nb.classes {
+		input[i] = copyFloats(nb.stats[class].mean)
+	}
+	return NewMatrix(input)
+}
+
+//get the smoothed variance of every column per class, row i belongs to Classes()[i-1]
+func (nb *GaussianNB) Variances() (*Matrix, error) {
+	if nb.classes == nil {
+		return nil, ErrNotFitted
+	}
+	return NewMatrix(nb.variances())
+}
+
+//calculate the log probability of every class, column j belongs to Classes()[j-1]
+func (nb *GaussianNB) PredictLogProba(x *Matrix) (*Matrix, error) {
+	return nb.logProba(x, nb.jointLogLikelihood())
+}
+
+//calculate the probability of every class, column j belongs to Classes()[j-1]
+func (nb *GaussianNB) PredictProba(x *Matrix) (*Matrix, error) {
+	return nb.proba(x, nb.jointLogLikelihood())
+}
+
+func (nb *GaussianNB) Predict(x *Matrix) (*Vector, error) {
+	return nb.predict(x, nb.jointLogLikelihood())
+}
+
+///////////////////////////
+////////DISCRETE//////////
+//////////////////////////
+
+//add the column values of every row to the counts of its class
+//transform is applied to every value before, e.g. to binarize it
+func (nb *nbBase) countFeatures(featureCounts map[float64][]float64, x *Matrix, y *Vector, transform func(float64) (float64, error)) error {
+	rows := x.rowSlices()
+	values := make([][]float64, len(rows))
+	for i, row := range rows {
+		values[i] = make([]float64, len(row))
+		for j, f := range row {
+			v, err := transform(f)
+			if err != nil {
+				return fmt.Errorf("Row %d: %s", i+1, err)
+			}
+			values[i][j] = v
+		}
+	}
+
+	//the batch is only counted once it is valid
+	for i, row := range values {
+		label := y.val[i]
+		if _, ok := featureCounts[label]; !ok {
+			featureCounts[label] = make([]float64, len(row))
+		}
+
+		nb.addRow(label)
+		for j, f := range row {
+			featureCounts[label][j] += f
+		}
+	}
+
+	nb.updateClasses(x.GetColumnNumber())
+	return nil
+}
+
+func nonNegative(f float64) (float64, error) {
+	if f < 0 {
+		return 0, fmt.Errorf("Counts should not be negative")
+	}
+	return f, nil
+}
+
+//train from scratch on x and y
+func (nb *MultinomialNB) Fit(x *Matrix, y *Vector) error {
+	nb.reset()
+	nb.featureCounts = nil
+	return nb.PartialFit(x, y)
+}
+
+//add the counts of a new batch, all values should be non negative
+func (nb *MultinomialNB) PartialFit(x *Matrix, y *Vector) error {
+	if err := validateAlpha(nb.Alpha); err != nil {
+		return err
+	}
+
+	if err := nb.validateInput(x, y); err != nil {
+		return err
+	}
+
+	if nb.featureCounts == nil {
+		nb.featureCounts = make(map[float64][]float64)
+	}
+
+	return nb.countFeatures(nb.featureCounts, x, y, nonNegative)
+}
+
+//get log P(column j | class) = log((Ncj + alpha) / (Nc + alpha * n)) of every class
+func (nb *MultinomialNB) featureLogProb() [][]float64 {
+	result := make([][]float64, len(nb.classes))
+	for i, class := range nb.classes {
+		counts := nb.featureCounts[class]
+		var total float64
+		for _, c := range counts {
+			total += c
+		}
+
+		result[i] = make([]float64, len(counts))
+		for j, c := range counts {
+			result[i][j] = math.Log((c + nb.Alpha) / (total + nb.Alpha*float64(len(counts))))
+		}
+	}
+	return result
+}
+
+//log prior + sigma(1...n) xj * log P(column j | class) for every class
+func (nb *MultinomialNB) jointLogLikelihood() func(row []float64) []float64 {
+	priors := nb.logPriors()
+	logProb := nb.featureLogProb()
+
+	return func(row []float64) []float64 {
+		result := make([]float64, len(nb.classes))
+		for i := range nb.classes {
+			result[i] = priors[i]
+			for j, f := range row {
+				result[i] += f * logProb[i][j]
+			}
+		}
+		return result
+	}
+}
+
+//calculate the log probability of every class, column j belongs to Classes()[j-1]
+func (nb *MultinomialNB) PredictLogProba(x *Matrix) (*Matrix, error) {
+	return nb.logProba(x, nb.jointLogLikelihood())
+}
+
+//calculate the probability of every class, column j belongs to Classes()[j-1]
+func (nb *MultinomialNB) PredictProba(x *Matrix) (*Matrix, error) {
+	return nb.proba(x, nb.jointLogLikelihood())
+}
+
+func (nb *MultinomialNB) Predict(x *Matrix) (*Vector, error) {
+	return nb.predict(x, nb.jointLogLikelihood())
+}
+
+func (nb *BernoulliNB) binarize(f float64) (float64, error) {
+	if f > nb.Binarize {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+//train from scratch on x and y
+func (nb *BernoulliNB) Fit(x *Matrix, y *Vector) error {
+	nb.reset()
+	nb.featureCounts = nil
+	return nb.PartialFit(x, y)
+}
+
+//count the binarized values of a new batch
+func (nb *BernoulliNB) PartialFit(x *Matrix, y *Vector) error {
+	if err := validateAlpha(nb.Alpha); err != nil {
+		return err
+	}
+
+	if err := nb.validateInput(x, y); err != nil {
+		return err
+	}
+
+	if nb.featureCounts == nil {
+		nb.featureCounts = make(map[float64][]float64)
+	}
+
+	return nb.countFeatures(nb.featureCounts, x, y, nb.binarize)
+}
+
+//log prior + sigma(1...n) log(pj) if xj is 1 or log(1 - pj) if xj is 0
+//with pj = (Ncj + alpha) / (Nc + 2 * alpha)
+func (nb *BernoulliNB) jointLogLikelihood() func(row []float64) []float64 {
+	priors := nb.logPriors()
+	logP := make([][]float64, len(nb.classes))
+	logNotP := make([][]float64, len(nb.classes))
+	for i, class := range nb.classes {
+		counts := nb.featureCounts[class]
+		logP[i] = make([]float64, len(counts))
+		logNotP[i] = make([]float64, len(counts))
+		for j, c := range counts {
+			p := (c + nb.Alpha) / (nb.counts[class] + 2*nb.Alpha)
+			logP[i][j], logNotP[i][j] = math.Log(p), math.Log(1-p)
+		}
+	}
+
+	return func(row []float64) []float64 {
+		result := make([]float64, len(nb.classes))
+		for i := range nb.classes {
+			result[i] = priors[i]
+			for j, f := range row {
+				if b, _ := nb.binarize(f); b == 1 {
+					result[i] += logP[i][j]
+				} else {
+					result[i] += logNotP[i][j]
+				}
+			}
+		}
+		return result
+	}
+}
+
+//calculate the log probability of every class, column j belongs to Classes()[j-1]
+func (nb *BernoulliNB) PredictLogProba(x *Matrix) (*Matrix, error) {
+	return nb.logProba(x, nb.jointLogLikelihood())
+}
+
+//calculate the probability of every class, column j belongs to Classes()[j-1]
+func (nb *BernoulliNB) PredictProba(x *Matrix) (*Matrix, error) {
+	return nb.proba(x, nb.jointLogLikelihood())
+}
+
+func (nb *BernoulliNB) Predict(x *Matrix) (*Vector, error) {
+	return nb.predict(x, nb.jointLogLikelihood())
+}
@@ -0,0 +1,151 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGaussianNB(t *testing.T) {
+	nb := NewGaussianNB()
+	_, err := nb.Predict(NewConstantMatrix(1, 2, 0))
+	assert.Equal(t, ErrNotFitted, err)
+
+	x, _ := NewMatrix([][]float64{{1, 9}, {3, 11}, {6, 20}, {8, 22}})
+	y := NewVector([]float64{0, 0, 1, 1})
+	assert.NoError(t, nb.Fit(x, y))
+	assert.Equal(t, []float64{0, 1}, nb.Classes())
+
+	means, _ := nb.Means()
+	assert.Equal(t, []float64{2, 10}, means.val[1].val)
+	assert.Equal(t, []float64{7, 21}, means.val[2].val)
+
+	variances, _ := nb.Variances()
+	assert.InDeltaSlice(t, []float64{1, 1}, variances.val[1].val, 1e-6)
+	assert.InDeltaSlice(t, []float64{1, 1}, variances.val[2].val, 1e-6)
+
+	query, _ := NewMatrix([][]float64{{2, 11}, {7, 19}, {1000, -1000}})
+	result, err := nb.Predict(query)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 1}, result.val[:2])
+
+	//far away rows would underflow outside of log space
+	proba, _ := nb.PredictProba(query)
+	for i := 1; i <= 3; i++ {
+		row := proba.val[i].val
+		assert.False(t, math.IsNaN(row[0]) || math.IsNaN(row[1]))
+		assert.InDelta(t, 1, row[0]+row[1], 1e-12)
+	}
+
+	logProba, _ := nb.PredictLogProba(query)
+	assert.InDelta(t, math.Log(proba.val[1].val[0]), logProba.val[1].val[0], 1e-12)
+
+	_, err = nb.Predict(NewConstantMatrix(1, 3, 0))
+	assert.Error(t, err)
+	assert.Error(t, nb.PartialFit(NewConstantMatrix(1, 3, 0), NewVector([]float64{1})))
+}
+
+func TestGaussianNBPartialFit(t *testing.T) {
+	x, _ := LoadNewMatrix("data1.csv", ":", "1:2")
+	y, _ := LoadNewVector("data1.csv", ":", "3")
+
+	full := NewGaussianNB()
+	full.Fit(x, y)
+
+	//batches give the same statistics as training at once
+	partial := NewGaussianNB()
+	for _, spec := range []string{"1:30", "31:70", "71:100"} {
+		bx, _ := LoadNewMatrix("data1.csv", spec, "1:2")
+		by, _ := LoadNewVector("data1.csv", spec, "3")
+		assert.NoError(t, partial.PartialFit(bx, by))
+	}
+
+	fullMeans, _ := full.Means()
+	partialMeans, _ := partial.Means()
+	fullVar, _ := full.Variances()
+	partialVar, _ := partial.Variances()
+	for i := 1; i <= 2; i++ {
+		assert.InDeltaSlice(t, fullMeans.val[i].val, partialMeans.val[i].val, 1e-9)
+		assert.InDeltaSlice(t, fullVar.val[i].val, partialVar.val[i].val, 1e-9)
+	}
+
+	folds, _ := NewStratifiedKFold(5, true, 1)
+	result, err := CrossValidate(func() Estimator { return NewGaussianNB() }, x, y, folds, Accuracy)
+	assert.NoError(t, err)
+	fmt.Printf("Gaussian naive bayes accuracy on data1.csv: %v\n", result.Mean)
+	assert.True(t, result.Mean > 0.8)
+}
+
+//word counts of the columns chinese, beijing, shanghai, macao, tokyo, japan
+//the class is 1 for documents about china
+func newTextData() (*Matrix, *Vector, *Matrix) {
+	x, _ := NewMatrix([][]float64{
+		{2, 1, 0, 0, 0, 0},
+		{2, 0, 1, 0, 0, 0},
+		{1, 0, 0, 1, 0, 0},
+		{1, 0, 0, 0, 1, 1},
+	})
+	y := NewVector([]float64{1, 1, 1, 0})
+	query, _ := NewMatrix([][]float64{{3, 0, 0, 0, 1, 1}})
+	return x, y, query
+}
+
+func TestMultinomialNB(t *testing.T) {
+	_, err := NewMultinomialNB(0)
+	assert.Error(t, err)
+
+	x, y, query := newTextData()
+	nb, _ := NewMultinomialNB(1)
+	assert.NoError(t, nb.Fit(x, y))
+
+	//P(1|d) ~ 3/4 * (3/7)^3 * (1/14)^2 and P(0|d) ~ 1/4 * (2/9)^5
+	yes := 0.75 * math.Pow(3.0/7, 3) * math.Pow(1.0/14, 2)
+	no := 0.25 * math.Pow(2.0/9, 5)
+	proba, err := nb.PredictProba(query)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{no / (yes + no), yes / (yes + no)}, proba.val[1].val, 1e-12)
+
+	result, _ := nb.Predict(query)
+	assert.Equal(t, []float64{1}, result.val)
+
+	//counts can't be negative and a failed batch changes nothing
+	negative, _ := NewMatrix([][]float64{{-1, 0, 0, 0, 0, 0}})
+	assert.Error(t, nb.PartialFit(negative, NewVector([]float64{1})))
+	proba2, _ := nb.PredictProba(query)
+	assert.Equal(t, proba, proba2)
+
+	//a new class can be added later
+	newClass, _ := NewMatrix([][]float64{{0, 0, 0, 0, 5, 5}})
+	assert.NoError(t, nb.PartialFit(newClass, NewVector([]float64{2})))
+	assert.Equal(t, []float64{0, 1, 2}, nb.Classes())
+}
+
+func TestBernoulliNB(t *testing.T) {
+	_, err := NewBernoulliNB(-1, 0)
+	assert.Error(t, err)
+
+	x, y, query := newTextData()
+	nb, _ := NewBernoulliNB(1, 0)
+	assert.NoError(t, nb.Fit(x, y))
+
+	//unlike the multinomial model the missing words count as well
+	yes := 0.75 * 0.8 * 0.2 * 0.2 * math.Pow(0.6, 3)
+	no := 0.25 * math.Pow(2.0/3, 6)
+	proba, err := nb.PredictProba(query)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{no / (yes + no), yes / (yes + no)}, proba.val[1].val, 1e-12)
+
+	result, _ := nb.Predict(query)
+	assert.Equal(t, []float64{0}, result.val)
+
+	//partial fits in two batches are the same as a single fit
+	partial, _ := NewBernoulliNB(1, 0)
+	first, _ := x.SelectRows([]int{1, 2})
+	second, _ := x.SelectRows([]int{3, 4})
+	partial.PartialFit(first, NewVector([]float64{1, 1}))
+	partial.PartialFit(second, NewVector([]float64{1, 0}))
+	proba2, _ := partial.PredictProba(query)
+	assert.InDeltaSlice(t, proba.val[1].val, proba2.val[1].val, 1e-12)
+}
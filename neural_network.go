@@ -0,0 +1,539 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type (
+	//Activation is the non-linearity applied to the output of a dense layer
+	Activation int
+
+	//WeightInit selects how the weights are drawn before training, biases start at 0
+	WeightInit int
+
+	//Layer configures a hidden layer with Size units
+	//Dropout is the share of units switched off at random for every row during training
+	Layer struct {
+		Size       int
+		Activation Activation
+		Dropout    float64
+	}
+
+	//denseLayer computes act(w * in + b), w has a row per unit
+	denseLayer struct {
+		w       [][]float64
+		b       []float64
+		act     Activation
+		dropout float64
+		//velocity and accumulated gradient of the current batch
+		vw, gw [][]float64
+		vb, gb []float64
+	}
+
+	//mlp holds the shared parameters and state of both networks
+	//training is mini-batch gradient descent with momentum on the mean loss of a batch
+	//plus Lambda / 2 * sigma(w^2), the biases are not regularized
+	mlp struct {
+		Layers       []Layer
+		LearningRate float64
+		Momentum     float64
+		Epochs       int
+		BatchSize    int
+		Lambda       float64
+		Init         WeightInit
+		Seed         int64
+
+		layers    []*denseLayer
+		lossCurve []float64
+	}
+
+	//MLPClassifier is a feedforward network with a softmax output unit per class
+	//trained on the cross entropy
+	MLPClassifier struct {
+		mlp
+		classes []float64
+	}
+
+	//MLPRegressor is a feedforward network with a single linear output unit
+	//trained on half the squared error
+	MLPRegressor struct {
+		mlp
+	}
+)
+
+const (
+	//1 / (1 + e^-z) using sigm
+	SigmoidActivation Activation = iota
+	TanhActivation
+	//max(0, z)
+	ReLUActivation
+	//e^zi / sigma(e^zj), only for the output layer
+	SoftmaxActivation
+	//z, only for the output layer
+	IdentityActivation
+)
+
+const (
+	//uniform in +-sqrt(6 / (in + out)), suits sigmoid and tanh
+	XavierInit WeightInit = iota
+	//normal with standard deviation sqrt(2 / in), suits relu
+	HeInit
+	//normal with standard deviation 0.01
+	NormalInit
+)
+
+func (a Activation) String() string {
+	switch a {
+	case SigmoidActivation:
+		return "sigmoid"
+	case TanhActivation:
+		return "tanh"
+	case ReLUActivation:
+		return "relu"
+	case SoftmaxActivation:
+		return "softmax"
+	case IdentityActivation:
+		return "identity"
+	}
+	return fmt.Sprintf("Activation(%d)", int(a))
+}
+
+//apply the activation to z in place
+func (a Activation) apply(z []float64) {
+	switch a {
+	case SigmoidActivation:
+		for i, v := range z {
+			z[i] = sigm(v)
+		}
+	case TanhActivation:
+		for i, v := range z {
+			z[i] = math.Tanh(v)
+		}
+	case ReLUActivation:
+		for i, v := range z {
+			z[i] = math.Max(0, v)
+		}
+	case SoftmaxActivation:
+		norm := logSumExp(z)
+		for i, v := range z {
+			z[i] = math.Exp(v - norm)
+		}
+	}
+}
+
+//derivative of a hidden activation expressed by its output
+func (a Activation) derivative(out float64) float64 {
+	switch a {
+	case SigmoidActivation:
+		return out * (1 - out)
+	case TanhActivation:
+		return 1 - out*out
+	case ReLUActivation:
+		if out > 0 {
+			return 1
+		}
+		return 0
+	}
+	return 1
+}
+
+//create a network with one hidden layer of 16 relu units
+func newMLP(learningRate float64, epochs int, seed int64) mlp {
+	return mlp{
+		Layers:       []Layer{{Size: 16, Activation: ReLUActivation}},
+		LearningRate: learningRate,
+		Momentum:     0.9,
+		Epochs:       epochs,
+		BatchSize:    32,
+		Init:         HeInit,
+		Seed:         seed,
+	}
+}
+
+//create a classifier with one hidden layer of 16 relu units, momentum 0.9 and batches of 32 rows
+func NewMLPClassifier(learningRate float64, epochs int, seed int64) (*MLPClassifier, error) {
+	m := &MLPClassifier{mlp: newMLP(learningRate, epochs, seed)}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//create a regressor with one hidden layer of 16 relu units, momentum 0.9 and batches of 32 rows
+func NewMLPRegressor(learningRate float64, epochs int, seed int64) (*MLPRegressor, error) {
+	m := &MLPRegressor{mlp: newMLP(learningRate, epochs, seed)}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *mlp) validate() error {
+	for i, l := range m.Layers {
+		if l.Size < 1 {
+			return fmt.Errorf("Layer %d: size should be at least 1", i+1)
+		}
+
+		if l.Activation < SigmoidActivation || l.Activation > ReLUActivation {
+			return fmt.Errorf("Layer %d: activation %v can't be used for a hidden layer", i+1, l.Activation)
+		}
+
+		if l.Dropout < 0 || l.Dropout >= 1 {
+			return fmt.Errorf("Layer %d: dropout should be in range [0, 1)", i+1)
+		}
+	}
+
+	if m.LearningRate <= 0 {
+		return fmt.Errorf("Learning rate should be greater than 0")
+	}
+
+	if err := validateMomentum(m.Momentum); err != nil {
+		return err
+	}
+
+	if m.Epochs < 1 {
+		return fmt.Errorf("Number of epochs should be at least 1")
+	}
+
+	if m.BatchSize < 1 {
+		return fmt.Errorf("Batch size should be at least 1")
+	}
+
+	if m.Lambda < 0 {
+		return fmt.Errorf("Lambda should not be negative")
+	}
+
+	if m.Init < XavierInit || m.Init > NormalInit {
+		return fmt.Errorf("Unknown weight initialization %d", m.Init)
+	}
+
+	return nil
+}
+
+func newDenseLayer(in, out int, act Activation, dropout float64, init WeightInit, rnd *rand.Rand) *denseLayer {
+	newMatrix := func() [][]float64 {
+		result := make([][]float64, out)
+		for i := range result {
+			result[i] = make([]float64, in)
+		}
+		return result
+	}
+
+	l := &denseLayer{w: newMatrix(), b: make([]float64, out), act: act, dropout: dropout,
+		vw: newMatrix(), vb: make([]float64, out), gw: newMatrix(), gb: make([]float64, out)}
+
+	for _, row := range l.w {
+		for j := range row {
+			switch init {
+			case XavierInit:
+				limit := math.Sqrt(6 / float64(in+out))
+				row[j] = (rnd.Float64()*2 - 1) * limit
+			case HeInit:
+				row[j] = rnd.NormFloat64() * math.Sqrt(2/float64(in))
+			default:
+				row[j] = rnd.NormFloat64() * 0.01
+			}
+		}
+	}
+	return l
+}
+
+//create the layers for in inputs and out output units
+func (m *mlp) initLayers(in, out int, outAct Activation, rnd *rand.Rand) {
+	m.layers = nil
+	for _, l := range m.Layers {
+		m.layers = append(m.layers, newDenseLayer(in, l.Size, l.Activation, l.Dropout, m.Init, rnd))
+		in = l.Size
+	}
+	m.layers = append(m.layers, newDenseLayer(in, out, outAct, 0, m.Init, rnd))
+}
+
+//draw the units kept by dropout for a single row, true means kept
+//layers without dropout get a nil mask
+func (m *mlp) dropMasks(rnd *rand.Rand) [][]bool {
+	masks := make([][]bool, len(m.layers))
+	for k, l := range m.layers {
+		if l.dropout == 0 {
+			continue
+		}
+
+		masks[k] = make([]bool, len(l.b))
+		for i := range masks[k] {
+			masks[k][i] = rnd.Float64() >= l.dropout
+		}
+	}
+	return masks
+}
+
+//calculate the output of every layer for row
+//outputs are the inputs of the next layer, where masked units are 0 and the kept ones
+//are scaled by 1 / (1 - dropout), acts are the activations before dropout
+//without masks both are the same
+func (m *mlp) forward(row []float64, masks [][]bool) (outputs, acts [][]float64) {
+	outputs, acts = [][]float64{row}, [][]float64{row}
+	in := row
+	for k, l := range m.layers {
+		act := make([]float64, len(l.b))
+		for i, w := range l.w {
+			act[i] = dotFloats(w, in) + l.b[i]
+		}
+		l.act.apply(act)
+
+		out := act
+		if masks != nil && masks[k] != nil {
+			out = make([]float64, len(act))
+			for i, kept := range masks[k] {
+				if kept {
+					out[i] = act[i] / (1 - l.dropout)
+				}
+			}
+		}
+
+		outputs, acts = append(outputs, out), append(acts, act)
+		in = out
+	}
+	return outputs, acts
+}
+
+//add the gradient of a single row to the layers
+//delta of the output layer is output - target for both softmax with cross entropy
+//and identity with squared error
+//masks should be the same as for forward
+func (m *mlp) backward(outputs, acts [][]float64, masks [][]bool, target []float64) {
+	last := outputs[len(outputs)-1]
+	delta := make([]float64, len(last))
+	for i := range delta {
+		delta[i] = last[i] - target[i]
+	}
+
+	for k := len(m.layers) - 1; k >= 0; k-- {
+		l, in := m.layers[k], outputs[k]
+		for i, d := range delta {
+			l.gb[i] += d
+			for j, a := range in {
+				l.gw[i][j] += d * a
+			}
+		}
+
+		if k == 0 {
+			break
+		}
+
+		//dropped units get no gradient, kept ones are scaled like their output
+		prev := m.layers[k-1]
+		var mask []bool
+		if masks != nil {
+			mask = masks[k-1]
+		}
+
+		next := make([]float64, len(in))
+		for j, a := range acts[k] {
+			scale := float64(1)
+			if mask != nil {
+				if !mask[j] {
+					continue
+				}
+				scale = 1 / (1 - prev.dropout)
+			}
+
+			var sum float64
+			for i, d := range delta {
+				sum += l.w[i][j] * d
+			}
+			next[j] = sum * prev.act.derivative(a) * scale
+		}
+		delta = next
+	}
+}
+
+//update the weights with the mean gradient of n rows and reset the gradients
+func (m *mlp) step(n int) {
+	for _, l := range m.layers {
+		for i, row := range l.w {
+			for j := range row {
+				g := l.gw[i][j]/float64(n) + m.Lambda*row[j]
+				l.vw[i][j] = m.Momentum*l.vw[i][j] - m.LearningRate*g
+				row[j] += l.vw[i][j]
+				l.gw[i][j] = 0
+			}
+
+			l.vb[i] = m.Momentum*l.vb[i] - m.LearningRate*l.gb[i]/float64(n)
+			l.b[i] += l.vb[i]
+			l.gb[i] = 0
+		}
+	}
+}
+
+//mean loss of the outputs plus the L2 penalty
+func (m *mlp) cost(rows, targets [][]float64, loss func(out, target []float64) float64) float64 {
+	var result float64
+	for i, row := range rows {
+		outputs, _ := m.forward(row, nil)
+		result += loss(outputs[len(outputs)-1], targets[i])
+	}
+	result /= float64(len(rows))
+
+	for _, l := range m.layers {
+		for _, w := range l.w {
+			result += m.Lambda / 2 * dotFloats(w, w)
+		}
+	}
+	return result
+}
+
+//train the network on rows and targets, the loss is recorded after every epoch
+func (m *mlp) fit(rows, targets [][]float64, outAct Activation, loss func(out, target []float64) float64) {
+	rnd := rand.New(rand.NewSource(m.Seed))
+	m.initLayers(len(rows[0]), len(targets[0]), outAct, rnd)
+	m.lossCurve = nil
+
+	for e := 0; e < m.Epochs; e++ {
+		order := rnd.Perm(len(rows))
+		for start := 0; start < len(order); start += m.BatchSize {
+			batch := order[start:minInt(start+m.BatchSize, len(order))]
+			for _, i := range batch {
+				masks := m.dropMasks(rnd)
+				outputs, acts := m.forward(rows[i], masks)
+				m.backward(outputs, acts, masks, targets[i])
+			}
+			m.step(len(batch))
+		}
+
+		m.lossCurve = append(m.lossCurve, m.cost(rows, targets, loss))
+	}
+}
+
+//calculate the outputs of the last layer for every row of x
+func (m *mlp) predictRaw(x *Matrix) ([][]float64, error) {
+	if m.layers == nil {
+		return nil, ErrNotFitted
+	}
+
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+
+	numFeatures := len(m.layers[0].w[0])
+	if x.GetColumnNumber() != numFeatures {
+		return nil, fmt.Errorf("Input dimension(%d) does not agree with training data(%d)",
+			x.GetColumnNumber(), numFeatures)
+	}
+
+	rows := x.rowSlices()
+	result := make([][]float64, len(rows))
+	parallelRows(len(rows), len(m.layers)*numFeatures, func(from, to int) {
+		for i := from; i < to; i++ {
+			outputs, _ := m.forward(rows[i], nil)
+			result[i] = outputs[len(outputs)-1]
+		}
+	})
+	return result, nil
+}
+
+//get the loss on the training data after every epoch
+func (m *mlp) LossCurve() *Vector { return NewVector(copyFloats(m.lossCurve)) }
+
+func crossEntropy(out, target []float64) float64 {
+	var result float64
+	for i, t := range target {
+		if t > 0 {
+			result -= t * math.Log(math.Max(out[i], 1e-15))
+		}
+	}
+	return result
+}
+
+func halfSquaredError(out, target []float64) float64 {
+	d := out[0] - target[0]
+	return d * d / 2
+}
+
+///////////////////////////
+////////CLASSIFIER////////
+//////////////////////////
+
+func (m *MLPClassifier) Fit(x *Matrix, y *Vector) error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+
+	if err := validateTreeInput(x, y); err != nil {
+		return err
+	}
+
+	m.classes = uniqueSorted(y.val)
+	index := classIndexOf(y.val, m.classes)
+
+	//one-hot encoded labels
+	targets := make([][]float64, len(index))
+	for i, c := range index {
+		targets[i] = make([]float64, len(m.classes))
+		targets[i][int(c)] = 1
+	}
+
+	m.fit(x.rowSlices(), targets, SoftmaxActivation, crossEntropy)
+	return nil
+}
+
+//get the sorted labels seen by Fit
+func (m *MLPClassifier) Classes() []float64 { return copyFloats(m.classes) }
+
+//calculate the probability of every class, column j belongs to Classes()[j-1]
+func (m *MLPClassifier) PredictProba(x *Matrix) (*Matrix, error) {
+	raw, err := m.predictRaw(x)
+	if err != nil {
+		return nil, err
+	}
+	return NewMatrix(raw)
+}
+
+//predict the class with the greatest probability
+func (m *MLPClassifier) Predict(x *Matrix) (*Vector, error) {
+	raw, err := m.predictRaw(x)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewZeroVector(len(raw))
+	for i, p := range raw {
+		result.val[i] = m.classes[argmax(p)]
+	}
+	return result, nil
+}
+
+///////////////////////////
+////////REGRESSOR/////////
+//////////////////////////
+
+func (m *MLPRegressor) Fit(x *Matrix, y *Vector) error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+
+	if err := validateTreeInput(x, y); err != nil {
+		return err
+	}
+
+	targets := make([][]float64, y.GetLength())
+	for i, v := range y.val {
+		targets[i] = []float64{v}
+	}
+
+	m.fit(x.rowSlices(), targets, IdentityActivation, halfSquaredError)
+	return nil
+}
+
+func (m *MLPRegressor) Predict(x *Matrix) (*Vector, error) {
+	raw, err := m.predictRaw(x)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewZeroVector(len(raw))
+	for i, out := range raw {
+		result.val[i] = out[0]
+	}
+	return result, nil
+}
@@ -0,0 +1,245 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivation(t *testing.T) {
+	z := []float64{-1, 0, 2}
+
+	out := copyFloats(z)
+	ReLUActivation.apply(out)
+	assert.Equal(t, []float64{0, 0, 2}, out)
+
+	out = copyFloats(z)
+	SigmoidActivation.apply(out)
+	assert.Equal(t, sigm(2), out[2])
+	assert.InDelta(t, sigm(2)*(1-sigm(2)), SigmoidActivation.derivative(out[2]), 1e-12)
+
+	out = copyFloats(z)
+	TanhActivation.apply(out)
+	assert.InDelta(t, 1-math.Pow(math.Tanh(-1), 2), TanhActivation.derivative(out[0]), 1e-12)
+
+	out = copyFloats(z)
+	SoftmaxActivation.apply(out)
+	assert.InDelta(t, 1, out[0]+out[1]+out[2], 1e-12)
+	assert.InDelta(t, math.Exp(2)/(math.Exp(-1)+1+math.Exp(2)), out[2], 1e-12)
+
+	assert.Equal(t, "relu", ReLUActivation.String())
+}
+
+//mean cross entropy plus the L2 penalty with a fixed dropout mask of every row
+func maskedCost(m *mlp, rows, targets [][]float64, masks [][][]bool) float64 {
+	var result float64
+	for i, row := range rows {
+		outputs, _ := m.forward(row, masks[i])
+		result += crossEntropy(outputs[len(outputs)-1], targets[i])
+	}
+	result /= float64(len(rows))
+
+	for _, l := range m.layers {
+		for _, w := range l.w {
+			result += m.Lambda / 2 * dotFloats(w, w)
+		}
+	}
+	return result
+}
+
+//the gradient of backward should be the same as the finite differences of the cost
+//for dropout the mask of every row is drawn once and kept for the finite differences
+func TestMLPBackward(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	rows, targets := make([][]float64, 5), make([][]float64, 5)
+	for i := range rows {
+		rows[i] = []float64{rnd.NormFloat64(), rnd.NormFloat64(), rnd.NormFloat64()}
+		targets[i] = []float64{0, 0}
+		targets[i][i%2] = 1
+	}
+
+	for _, dropout := range []float64{0, 0.5} {
+		for _, act := range []Activation{SigmoidActivation, TanhActivation, ReLUActivation} {
+			m := &mlp{Layers: []Layer{{Size: 4, Activation: act, Dropout: dropout}, {Size: 3, Activation: act, Dropout: dropout}}, Lambda: 0.1}
+			m.initLayers(3, 2, SoftmaxActivation, rnd)
+			//biases of 0 would put relu units without input exactly on the kink
+			for _, l := range m.layers {
+				for i := range l.b {
+					l.b[i] = rnd.NormFloat64() * 0.1
+				}
+			}
+
+			masks := make([][][]bool, len(rows))
+			for i, row := range rows {
+				masks[i] = m.dropMasks(rnd)
+				outputs, acts := m.forward(row, masks[i])
+				m.backward(outputs, acts, masks[i], targets[i])
+			}
+
+			const eps = 1e-6
+			numeric := func(param *float64) float64 {
+				orig := *param
+				*param = orig + eps
+				plus := maskedCost(m, rows, targets, masks)
+				*param = orig - eps
+				minus := maskedCost(m, rows, targets, masks)
+				*param = orig
+				return (plus - minus) / (2 * eps)
+			}
+
+			for _, l := range m.layers {
+				for i, w := range l.w {
+					for j := range w {
+						grad := l.gw[i][j]/float64(len(rows)) + m.Lambda*w[j]
+						assert.InDelta(t, numeric(&w[j]), grad, 1e-6, "%v dropout %v", act, dropout)
+					}
+					assert.InDelta(t, numeric(&l.b[i]), l.gb[i]/float64(len(rows)), 1e-6, "%v dropout %v", act, dropout)
+				}
+			}
+		}
+	}
+}
+
+func TestNewMLP(t *testing.T) {
+	_, err := NewMLPClassifier(0, 10, 1)
+	assert.Error(t, err)
+	_, err = NewMLPRegressor(0.1, 0, 1)
+	assert.Error(t, err)
+
+	m, err := NewMLPClassifier(0.1, 10, 1)
+	assert.NoError(t, err)
+	_, err = m.Predict(NewConstantMatrix(1, 2, 0))
+	assert.Equal(t, ErrNotFitted, err)
+
+	x, y := newBlobs(blobCenters, 5, 1, 1)
+	for _, layers := range [][]Layer{
+		{{Size: 0, Activation: ReLUActivation}},
+		{{Size: 4, Activation: SoftmaxActivation}},
+		{{Size: 4, Activation: TanhActivation, Dropout: 1}},
+	} {
+		m.Layers = layers
+		assert.Error(t, m.Fit(x, y))
+	}
+
+	m.Layers = nil
+	m.BatchSize = 0
+	assert.Error(t, m.Fit(x, y))
+	m.BatchSize, m.Init = 10, WeightInit(5)
+	assert.Error(t, m.Fit(x, y))
+
+	//without hidden layers the classifier is a softmax regression
+	m.Init = XavierInit
+	assert.NoError(t, m.Fit(x, y))
+	_, err = m.Predict(NewConstantMatrix(1, 3, 0))
+	assert.Error(t, err)
+}
+
+func TestMLPClassifierXOR(t *testing.T) {
+	x, _ := NewMatrix([][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}})
+	y := NewVector([]float64{0, 1, 1, 0})
+
+	m, _ := NewMLPClassifier(0.1, 1000, 1)
+	m.Layers = []Layer{{Size: 8, Activation: TanhActivation}}
+	m.Init = XavierInit
+	m.BatchSize = 4
+	assert.NoError(t, m.Fit(x, y))
+
+	result, _ := m.Predict(x)
+	assert.Equal(t, y.val, result.val)
+
+	curve := m.LossCurve()
+	fmt.Printf("MLP loss on XOR: %v -> %v\n", curve.val[0], curve.val[curve.GetLength()-1])
+	assert.Equal(t, 1000, curve.GetLength())
+	assert.True(t, curve.val[999] < curve.val[0])
+}
+
+func TestMLPClassifier(t *testing.T) {
+	x, y := newBlobs(blobCenters, 50, 2, 1)
+	xTest, yTest := newBlobs(blobCenters, 20, 2, 2)
+
+	m, _ := NewMLPClassifier(0.01, 50, 1)
+	m.Layers = []Layer{{Size: 16, Activation: ReLUActivation, Dropout: 0.2}, {Size: 8, Activation: ReLUActivation}}
+	m.Lambda = 0.001
+	assert.NoError(t, m.Fit(x, y))
+	assert.Equal(t, []float64{1, 2, 3}, m.Classes())
+
+	result, _ := m.Predict(xTest)
+	acc, _ := Accuracy(result, yTest)
+	fmt.Printf("MLP accuracy on blobs: %v\n", acc)
+	assert.True(t, acc > 0.95)
+
+	proba, err := m.PredictProba(xTest)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, proba.GetColumnNumber())
+	for i := 1; i <= proba.GetRowNumber(); i++ {
+		row := proba.getRowVector(i)
+		assert.InDelta(t, 1, row.val[0]+row.val[1]+row.val[2], 1e-9)
+		assert.Equal(t, m.Classes()[argmax(row.val)], result.getSingleValue(i))
+	}
+
+	//the same seed gives the same network
+	other, _ := NewMLPClassifier(0.01, 50, 1)
+	other.Layers, other.Lambda = m.Layers, m.Lambda
+	other.Fit(x, y)
+	otherProba, _ := other.PredictProba(xTest)
+	assert.Equal(t, proba.rowSlices(), otherProba.rowSlices())
+}
+
+func TestMLPClassifierFromExData(t *testing.T) {
+	x, y := loadScaledData1()
+
+	factory := func() Estimator {
+		m, _ := NewMLPClassifier(0.05, 200, 1)
+		m.Layers = []Layer{{Size: 8, Activation: SigmoidActivation}}
+		m.Init = XavierInit
+		return m
+	}
+
+	splitter, _ := NewStratifiedKFold(5, true, 1)
+	res, err := CrossValidate(factory, x, y, splitter, Accuracy)
+	assert.NoError(t, err)
+	fmt.Printf("MLP cross validation accuracy on data1.csv:\n%s", res)
+	assert.True(t, res.Mean > 0.8)
+}
+
+func TestMLPRegressor(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	input, target := make([][]float64, 200), make([]float64, 200)
+	for i := range input {
+		input[i] = []float64{rnd.Float64()*4 - 2}
+		target[i] = input[i][0] * input[i][0]
+	}
+	x, _ := NewMatrix(input)
+	y := NewVector(target)
+
+	m, _ := NewMLPRegressor(0.01, 200, 1)
+	m.Layers = []Layer{{Size: 16, Activation: TanhActivation}}
+	m.Init = XavierInit
+	assert.NoError(t, m.Fit(x, y))
+
+	result, err := m.Predict(x)
+	assert.NoError(t, err)
+	r2, _ := R2Score(result, y)
+	fmt.Printf("MLP R^2 on x^2: %v\n", r2)
+	assert.True(t, r2 > 0.95)
+
+	curve := m.LossCurve()
+	assert.True(t, curve.val[curve.GetLength()-1] < curve.val[0])
+}
+
+func TestMLPRegressorFromExData(t *testing.T) {
+	x, y := loadData3(), loadData3Y()
+	x.MultiplyVariable(0.1)
+	xTrain, xTest, yTrain, yTest, _ := SequentialTrainTestSplit(x, y, 0.8)
+
+	m, _ := NewMLPRegressor(0.001, 200, 1)
+	assert.NoError(t, m.Fit(xTrain, yTrain))
+
+	result, _ := m.Predict(xTest)
+	mae, _ := MeanAbsoluteError(result, yTest)
+	fmt.Printf("MLP mean absolute error on data3.csv: %v\n", mae)
+	assert.True(t, mae < 5)
+}
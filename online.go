@@ -0,0 +1,44 @@
+package ml
+
+import "fmt"
+
+type (
+	//optimizer state that is kept between PartialFit calls
+	//velocity is the momentum term of the last step and steps the number of finished steps
+	sgdState struct {
+		momentum float64
+		decay    float64
+		velocity *Vector
+		steps    int
+	}
+)
+
+//calculate the new theta for grad and remember the velocity
+//the learning rate is alpha / (1 + decay * steps)
+func (s *sgdState) step(theta, grad *Vector, alpha float64) *Vector {
+	if s.velocity == nil || s.velocity.GetLength() != grad.GetLength() {
+		s.velocity = NewZeroVector(grad.GetLength())
+	}
+
+	//both have the length of grad
+	s.velocity, _ = s.velocity.Scale(s.momentum).Add(grad)
+	rate := alpha / (1 + s.decay*float64(s.steps))
+	s.steps++
+
+	newTheta, _ := theta.Add(s.velocity.Scale(-1 * rate))
+	return newTheta
+}
+
+func validateMomentum(momentum float64) error {
+	if momentum < 0 || momentum >= 1 {
+		return fmt.Errorf("Momentum should be in range [0, 1)")
+	}
+	return nil
+}
+
+func validateDecay(decay float64) error {
+	if decay < 0 {
+		return fmt.Errorf("Decay should not be negative")
+	}
+	return nil
+}
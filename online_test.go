@@ -0,0 +1,108 @@
+package ml
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSGDStateStep(t *testing.T) {
+	s := sgdState{momentum: 0.5, decay: 1}
+	theta := NewVector([]float64{1, 1})
+
+	//first step: velocity = grad, rate = alpha
+	theta = s.step(theta, NewVector([]float64{2, -2}), 0.1)
+	assert.InDeltaSlice(t, []float64{0.8, 1.2}, theta.val, 1e-12)
+
+	//second step: velocity = 0.5 * [2,-2] + [2,0] = [3,-1], rate = 0.1 / 2
+	theta = s.step(theta, NewVector([]float64{2, 0}), 0.1)
+	assert.InDeltaSlice(t, []float64{0.65, 1.25}, theta.val, 1e-12)
+	assert.Equal(t, 2, s.steps)
+}
+
+func TestLinearRegressionPartialFit(t *testing.T) {
+	file := "data3.csv"
+	x, _ := LoadNewMatrix(file, ":", "1")
+	y, _ := LoadNewVector(file, ":", "2")
+
+	lr, _ := NewLinearRegression(x, y, NewZeroVector(2), 0.01)
+	cost := lr.CostFunc()
+
+	assert.Error(t, lr.SetMomentum(1))
+	assert.Error(t, lr.SetDecay(-1))
+	assert.NoError(t, lr.SetMomentum(0.5))
+
+	//feed the data in batches of 10 rows for several passes
+	for pass := 0; pass < 5; pass++ {
+		for start := 1; start <= 97; start += 10 {
+			spec := fmt.Sprintf("%d:%d", start, start+9)
+			bx, _ := LoadNewMatrix(file, spec, "1")
+			by, _ := LoadNewVector(file, spec, "2")
+
+			err := lr.PartialFit(bx, by)
+			assert.NoError(t, err)
+			//the batch of the caller stays unchanged
+			assert.Equal(t, 1, bx.GetColumnNumber())
+		}
+	}
+	assert.Equal(t, 50, lr.GetSteps())
+	//the training data of the model is not replaced by the batches
+	assert.Equal(t, y.GetLength(), lr.y.GetLength())
+
+	x, _ = LoadNewMatrix(file, ":", "1")
+	full, _ := NewLinearRegression(x, y, lr.theta, 0.01)
+	fmt.Printf("Cost before %v after %v partial fits: %v\n", cost, lr.GetSteps(), full.CostFunc())
+	assert.True(t, full.CostFunc() < cost)
+
+	lr.ResetOptimizer()
+	assert.Equal(t, 0, lr.GetSteps())
+
+	//without momentum a partial fit is the same as a gradient descent step
+	x, _ = LoadNewMatrix(file, ":", "1")
+	gd, _ := NewLinearRegression(x, y, NewZeroVector(2), 0.01)
+	gd.UpdateGrad(3)
+
+	x, _ = LoadNewMatrix(file, ":", "1")
+	pf, _ := NewLinearRegression(x, y, NewZeroVector(2), 0.01)
+	x, _ = LoadNewMatrix(file, ":", "1")
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, pf.PartialFit(x, y))
+	}
+	assert.InDeltaSlice(t, gd.theta.val, pf.theta.val, 1e-12)
+
+	//dimensions should agree with theta
+	x, _ = LoadNewMatrix(file, ":", "1:2")
+	assert.Error(t, pf.PartialFit(x, y))
+	assert.Error(t, pf.PartialFit(&Matrix{}, y))
+}
+
+func TestLogisticRegressionPartialFit(t *testing.T) {
+	file := "data1.csv"
+	x, _ := LoadNewMatrix(file, ":", "1:2")
+	y, _ := LoadNewVector(file, ":", "3")
+
+	lreg, _ := NewLogisticRegression(x, y, NewZeroVector(3), 0.0001)
+	cost := lreg.CostFunc()
+	assert.NoError(t, lreg.SetDecay(0.01))
+
+	for pass := 0; pass < 10; pass++ {
+		for start := 1; start <= 100; start += 25 {
+			spec := fmt.Sprintf("%d:%d", start, start+24)
+			bx, _ := LoadNewMatrix(file, spec, "1:2")
+			by, _ := LoadNewVector(file, spec, "3")
+			assert.NoError(t, lreg.PartialFit(bx, by))
+		}
+	}
+	assert.Equal(t, 40, lreg.GetSteps())
+
+	x, _ = LoadNewMatrix(file, ":", "1:2")
+	full, _ := NewLogisticRegression(x, y, lreg.theta, 0.0001)
+	fmt.Printf("Cost before %v after %v partial fits: %v\n", cost, lreg.GetSteps(), full.CostFunc())
+	assert.True(t, full.CostFunc() < cost)
+
+	//labels should be 0 or 1
+	x, _ = LoadNewMatrix(file, ":", "1")
+	y, _ = LoadNewVector(file, ":", "2")
+	assert.Error(t, lreg.PartialFit(x, y))
+}
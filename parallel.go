@@ -0,0 +1,75 @@
+package ml
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+//number of goroutines used by matrix operations
+//defaults to the number of CPUs
+var numWorkers = int32(runtime.NumCPU())
+
+//operations with less work than this (number of rows * work per row)
+//run in the calling goroutine since starting workers would cost more than it saves
+const minParallelWork = 1 << 14
+
+//set the number of goroutines used by matrix operations
+//n lesser than 1 is treated as 1, i.e. everything runs sequentially
+func SetNumWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	atomic.StoreInt32(&numWorkers, int32(n))
+}
+
+func GetNumWorkers() int {
+	return int(atomic.LoadInt32(&numWorkers))
+}
+
+//partition the rows 0...n-1 into consecutive chunks and call fn for every chunk
+//with the range [from, to) in its own goroutine
+//workPerRow is a rough estimate of the operations per row to decide whether it's worth it
+func parallelRows(n, workPerRow int, fn func(from, to int)) {
+	workers := GetNumWorkers()
+	if workers > n {
+		workers = n
+	}
+
+	if workers <= 1 || n*workPerRow < minParallelWork {
+		fn(0, n)
+		return
+	}
+
+	var wg sync.WaitGroup
+	from := 0
+	for w := 0; w < workers; w++ {
+		//the first n % workers chunks get one more row
+		size := n / workers
+		if w < n%workers {
+			size++
+		}
+
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			fn(from, to)
+		}(from, from+size)
+		from += size
+	}
+	wg.Wait()
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
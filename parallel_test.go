@@ -0,0 +1,172 @@
+package ml
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRandomMatrix(numRow, numCol int, seed int64) *Matrix {
+	rnd := rand.New(rand.NewSource(seed))
+
+	var input [][]float64
+	for i := 0; i < numRow; i++ {
+		row := make([]float64, numCol)
+		for j := range row {
+			row[j] = rnd.Float64()*2 - 1
+		}
+		input = append(input, row)
+	}
+
+	m, _ := NewMatrix(input)
+	return m
+}
+
+//the straightforward triple loop as reference
+func naiveMultiply(m, m2 *Matrix) [][]float64 {
+	res := make([][]float64, m.GetRowNumber())
+	for i := 1; i <= m.GetRowNumber(); i++ {
+		res[i-1] = make([]float64, m2.GetColumnNumber())
+		for j := 1; j <= m2.GetColumnNumber(); j++ {
+			res[i-1][j-1] = m.getRowVector(i).dotProduct(m2.getColumnVector(j))
+		}
+	}
+	return res
+}
+
+func TestParallelRows(t *testing.T) {
+	defer SetNumWorkers(GetNumWorkers())
+
+	SetNumWorkers(0)
+	assert.Equal(t, 1, GetNumWorkers())
+
+	SetNumWorkers(3)
+	var calls, total int32
+	parallelRows(10, minParallelWork, func(from, to int) {
+		atomic.AddInt32(&calls, 1)
+		atomic.AddInt32(&total, int32(to-from))
+	})
+	assert.Equal(t, int32(3), calls)
+	assert.Equal(t, int32(10), total)
+
+	//too little work runs inline
+	calls = 0
+	parallelRows(10, 1, func(from, to int) { atomic.AddInt32(&calls, 1) })
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestParallelMultiply(t *testing.T) {
+	defer SetNumWorkers(GetNumWorkers())
+
+	//dimensions which are not a multiple of the block size
+	m := newRandomMatrix(150, 70, 1)
+	m2 := newRandomMatrix(70, 130, 2)
+	expected := naiveMultiply(m, m2)
+
+	for _, workers := range []int{1, 2, 7} {
+		SetNumWorkers(workers)
+
+		res, err := m.Multiply(m2)
+		assert.NoError(t, err)
+		assert.Equal(t, 150, res.GetRowNumber())
+		assert.Equal(t, 130, res.GetColumnNumber())
+		for i := 1; i <= res.GetRowNumber(); i++ {
+			assert.InDeltaSlice(t, expected[i-1], res.getRowVector(i).val, 1e-9)
+		}
+	}
+
+	//number of columns of m2 is lesser than m's
+	m3, _ := NewMatrix([][]float64{[]float64{1, 2, 3}, []float64{4, 5, 6}})
+	m4, _ := NewMatrix([][]float64{[]float64{1}, []float64{0}, []float64{2}})
+	res, err := m3.Multiply(m4)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{7}, res.getRowVector(1).val)
+	assert.Equal(t, []float64{16}, res.getRowVector(2).val)
+}
+
+func TestParallelElementOperations(t *testing.T) {
+	defer SetNumWorkers(GetNumWorkers())
+	SetNumWorkers(4)
+
+	m := newRandomMatrix(300, 100, 1)
+	original := m.Clone()
+
+	m.MultiplyVariable(2)
+	m.AddVariable(1)
+	m.AddMatrix(original)
+	m.Calculate(func(x float64) float64 { return x - 1 })
+
+	for i := 1; i <= m.GetRowNumber(); i++ {
+		for j := 1; j <= m.GetColumnNumber(); j++ {
+			assert.InDelta(t, 3*original.getSingleValue(i, j), m.getSingleValue(i, j), 1e-12)
+		}
+	}
+}
+
+func benchmarkWorkers() []int {
+	workers := []int{1}
+	for n := 2; n <= runtime.NumCPU(); n *= 2 {
+		workers = append(workers, n)
+	}
+	return workers
+}
+
+func BenchmarkMultiply(b *testing.B) {
+	defer SetNumWorkers(GetNumWorkers())
+
+	m := newRandomMatrix(256, 256, 1)
+	m2 := newRandomMatrix(256, 256, 2)
+
+	for _, workers := range benchmarkWorkers() {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			SetNumWorkers(workers)
+			for i := 0; i < b.N; i++ {
+				m.multiply(m2)
+			}
+		})
+	}
+}
+
+func BenchmarkNaiveMultiply(b *testing.B) {
+	m := newRandomMatrix(256, 256, 1)
+	m2 := newRandomMatrix(256, 256, 2)
+
+	for i := 0; i < b.N; i++ {
+		naiveMultiply(m, m2)
+	}
+}
+
+func BenchmarkAddMatrix(b *testing.B) {
+	defer SetNumWorkers(GetNumWorkers())
+
+	m := newRandomMatrix(2000, 500, 1)
+	m2 := newRandomMatrix(2000, 500, 2)
+
+	for _, workers := range benchmarkWorkers() {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			SetNumWorkers(workers)
+			for i := 0; i < b.N; i++ {
+				m.addMatrix(m2)
+			}
+		})
+	}
+}
+
+func BenchmarkCalculate(b *testing.B) {
+	defer SetNumWorkers(GetNumWorkers())
+
+	m := newRandomMatrix(2000, 500, 1)
+
+	for _, workers := range benchmarkWorkers() {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			SetNumWorkers(workers)
+			for i := 0; i < b.N; i++ {
+				m.Calculate(sigm)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package ml
+
+import (
+	"fmt"
+)
+
+type (
+	//LinearPredictor is an immutable copy of the theta of a trained LinReg
+	//it never modifies its input, so it's safe to use from multiple goroutines
+	//e.g. by HTTP handlers while the original model keeps training
+	LinearPredictor struct {
+		theta []float64
+	}
+
+	//LogisticPredictor is an immutable copy of the theta of a trained LReg
+	//rows with a probability >= threshold are predicted as 1
+	LogisticPredictor struct {
+		theta     []float64
+		threshold float64
+	}
+)
+
+//calculate theta1 + sigma(2..n)thetaj*xj-1 for a single row without the 1's column
+func linearCombination(theta, row []float64) float64 {
+	result := theta[0]
+	for j, x := range row {
+		result += theta[j+1] * x
+	}
+	return result
+}
+
+//validate the input of a predictor
+//x should be a valid matrix without the 1's column
+func validatePredictorInput(x *Matrix, theta []float64) error {
+	if err := x.validate(); err != nil {
+		return err
+	}
+
+	if x.GetColumnNumber()+1 != len(theta) {
+		return fmt.Errorf("Input vector dimension(%d) does not agree with theta(%d)",
+			x.GetColumnNumber()+1, len(theta))
+	}
+
+	return nil
+}
+
+///////////////////////////
+////////LINEAR////////////
+//////////////////////////
+
+//create an immutable predictor out of the current theta
+func (lr *LinReg) Snapshot() *LinearPredictor {
+	return &LinearPredictor{theta: copyFloats(lr.theta.val)}
+}
+
+//get a copy of theta
+func (p *LinearPredictor) Theta() *Vector {
+	return NewVector(copyFloats(p.theta))
+}
+
+//predict y for every row of x
+//x should not contain the 1's column
+func (p *LinearPredictor) Predict(x *Matrix) (*Vector, error) {
+	if err := validatePredictorInput(x, p.theta); err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, 0, x.GetRowNumber())
+	for i := 1; i <= x.GetRowNumber(); i++ {
+		result = append(result, linearCombination(p.theta, x.getRowVector(i).val))
+	}
+
+	return NewVector(result), nil
+}
+
+//predict y for a single row without the 1's column
+func (p *LinearPredictor) PredictVector(input *Vector) (float64, error) {
+	if input.GetLength()+1 != len(p.theta) {
+		return 0, ErrVectorFalseDimension
+	}
+
+	return linearCombination(p.theta, input.val), nil
+}
+
+//predict y for every row of a sparse x without the 1's column
+func (p *LinearPredictor) PredictSparse(x *SparseMatrix) (*Vector, error) {
+	if x.GetColumnNumber()+1 != len(p.theta) {
+		return nil, fmt.Errorf("Input vector dimension(%d) does not agree with theta(%d)",
+			x.GetColumnNumber()+1, len(p.theta))
+	}
+
+	return NewVector(sparseLinearCombination(x, p.theta)), nil
+}
+
+///////////////////////////
+////////LOGISTIC//////////
+//////////////////////////
+
+//create an immutable predictor out of the current theta with threshold 0.5
+func (lr *LReg) Snapshot() *LogisticPredictor {
+	return &LogisticPredictor{theta: copyFloats(lr.theta.val), threshold: 0.5}
+}
+
+//get a copy of theta
+func (p *LogisticPredictor) Theta() *Vector {
+	return NewVector(copyFloats(p.theta))
+}
+
+//create a new predictor with the same theta but another decision threshold
+//threshold should be between 0 and 1
+func (p *LogisticPredictor) WithThreshold(threshold float64) (*LogisticPredictor, error) {
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("Threshold should be between 0 and 1")
+	}
+
+	return &LogisticPredictor{theta: p.theta, threshold: threshold}, nil
+}
+
+//predict the probability of y = 1 for every row of x
+//x should not contain the 1's column
+func (p *LogisticPredictor) PredictProba(x *Matrix) (*Vector, error) {
+	if err := validatePredictorInput(x, p.theta); err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, 0, x.GetRowNumber())
+	for i := 1; i <= x.GetRowNumber(); i++ {
+		result = append(result, sigm(linearCombination(p.theta, x.getRowVector(i).val)))
+	}
+
+	return NewVector(result), nil
+}
+
+//predict either 0 or 1 for every row of x
+func (p *LogisticPredictor) Predict(x *Matrix) (*Vector, error) {
+	proba, err := p.PredictProba(x)
+	if err != nil {
+		return nil, err
+	}
+
+	proba.Calculate(func(x float64) float64 {
+		if x < p.threshold {
+			return 0
+		}
+		return 1
+	})
+	return proba, nil
+}
+
+//predict the probability of y = 1 for a single row without the 1's column
+func (p *LogisticPredictor) PredictVectorProba(input *Vector) (float64, error) {
+	if input.GetLength()+1 != len(p.theta) {
+		return 0, ErrVectorFalseDimension
+	}
+
+	return sigm(linearCombination(p.theta, input.val)), nil
+}
+
+//predict the probability of y = 1 for every row of a sparse x without the 1's column
+func (p *LogisticPredictor) PredictSparseProba(x *SparseMatrix) (*Vector, error) {
+	if x.GetColumnNumber()+1 != len(p.theta) {
+		return nil, fmt.Errorf("Input vector dimension(%d) does not agree with theta(%d)",
+			x.GetColumnNumber()+1, len(p.theta))
+	}
+
+	proba := NewVector(sparseLinearCombination(x, p.theta))
+	proba.Calculate(sigm)
+	return proba, nil
+}
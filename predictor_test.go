@@ -0,0 +1,94 @@
+package ml
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearPredictor(t *testing.T) {
+	file := "data3.csv"
+	x, _ := LoadNewMatrix(file, "1:80", "1")
+	y, _ := LoadNewVector(file, "1:80", "2")
+	theta := NewZeroVector(2)
+
+	lr, err := NewLinearRegression(x, y, theta, 0.01)
+	assert.NoError(t, err)
+
+	//training doesn't change the initial theta
+	lr.UpdateGrad(100)
+	assert.Equal(t, []float64{0, 0}, theta.val)
+
+	p := lr.Snapshot()
+	before := p.Theta()
+
+	//snapshot doesn't change while the model keeps training
+	lr.UpdateGrad(100)
+	assert.Equal(t, before, p.Theta())
+	assert.NotEqual(t, before.val, lr.theta.val)
+
+	xverif, _ := LoadNewMatrix(file, "81:100", "1")
+	_, err = p.Predict(NewZeroMatrix(3, 2))
+	assert.Error(t, err)
+
+	res, err := p.Predict(xverif)
+	assert.NoError(t, err)
+	assert.Equal(t, xverif.GetRowNumber(), res.GetLength())
+	assert.Equal(t, 1, xverif.GetColumnNumber())
+
+	single, err := p.PredictVector(xverif.getRowVector(1))
+	assert.NoError(t, err)
+	assert.Equal(t, res.getSingleValue(1), single)
+
+	//CalculateResult doesn't modify its input anymore
+	res2, err := lr.CalculateResult(xverif)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, xverif.GetColumnNumber())
+	assert.Equal(t, xverif.GetRowNumber(), res2.GetLength())
+}
+
+func TestLogisticPredictorConcurrent(t *testing.T) {
+	file := "data1.csv"
+	x, _ := LoadNewMatrix(file, "1:80", "1:2")
+	y, _ := LoadNewVector(file, "1:80", "3")
+
+	lreg, err := NewLogisticRegression(x, y, NewZeroVector(3), 0.001)
+	assert.NoError(t, err)
+	lreg.UpdateGrad(100, false)
+
+	p := lreg.Snapshot()
+	xverif, _ := LoadNewMatrix(file, "81:100", "1:2")
+	expected, err := p.Predict(xverif)
+	assert.NoError(t, err)
+
+	proba, err := p.PredictProba(xverif)
+	assert.NoError(t, err)
+	for i := 1; i <= proba.GetLength(); i++ {
+		assert.True(t, proba.getSingleValue(i) >= 0 && proba.getSingleValue(i) <= 1)
+	}
+
+	_, err = p.WithThreshold(2)
+	assert.Error(t, err)
+
+	p2, err := p.WithThreshold(1)
+	assert.NoError(t, err)
+	none, _ := p2.Predict(xverif)
+	assert.Equal(t, NewZeroVector(xverif.GetRowNumber()), none)
+
+	//predict concurrently while the model keeps training
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				res, err := p.Predict(xverif)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, res)
+			}
+		}()
+	}
+	lreg.UpdateGrad(50, false)
+	wg.Wait()
+}
@@ -0,0 +1,397 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+type (
+	//forest holds the trees and which rows every tree was trained on
+	forest struct {
+		trees       []*treeNode
+		inBag       [][]bool
+		importances []float64
+		numFeatures int
+	}
+
+	//RandomForestClassifier averages the class probabilities of NumTrees decision trees
+	//every tree is trained on a bootstrap sample and tries MaxFeatures random columns per split
+	//MaxFeatures 0 means sqrt of the number of columns
+	//trees are built by Workers goroutines, Seed makes the result independent of scheduling
+	RandomForestClassifier struct {
+		NumTrees        int
+		Criterion       Criterion
+		MaxDepth        int
+		MinSamplesSplit int
+		MinSamplesLeaf  int
+		MaxFeatures     int
+		Bootstrap       bool
+		OOBScore        bool
+		Seed            int64
+		Workers         int
+
+		forest   *forest
+		classes  []float64
+		oobScore float64
+	}
+
+	//RandomForestRegressor averages the predictions of NumTrees regression trees
+	//MaxFeatures 0 means a third of the number of columns, the rest is the same as RandomForestClassifier
+	RandomForestRegressor struct {
+		NumTrees        int
+		Criterion       Criterion
+		MaxDepth        int
+		MinSamplesSplit int
+		MinSamplesLeaf  int
+		MaxFeatures     int
+		Bootstrap       bool
+		OOBScore        bool
+		Seed            int64
+		Workers         int
+
+		forest   *forest
+		oobScore float64
+	}
+)
+
+//create a random forest of fully grown gini trees with bootstrap samples and out-of-bag score
+func NewRandomForestClassifier(numTrees int, seed int64) (*RandomForestClassifier, error) {
+	if err := validateForest(numTrees); err != nil {
+		return nil, err
+	}
+
+	return &RandomForestClassifier{NumTrees: numTrees, Criterion: GiniCriterion, MinSamplesSplit: 2, MinSamplesLeaf: 1,
+		Bootstrap: true, OOBScore: true, Seed: seed, Workers: GetNumWorkers()}, nil
+}
+
+//create a random forest of fully grown mse trees with bootstrap samples and out-of-bag score
+func NewRandomForestRegressor(numTrees int, seed int64) (*RandomForestRegressor, error) {
+	if err := validateForest(numTrees); err != nil {
+		return nil, err
+	}
+
+	return &RandomForestRegressor{NumTrees: numTrees, Criterion: MSECriterion, MinSamplesSplit: 2, MinSamplesLeaf: 1,
+		Bootstrap: true, OOBScore: true, Seed: seed, Workers: GetNumWorkers()}, nil
+}
+
+//build numTrees trees with a pool of workers
+//the seed of every tree is drawn in advance so the forest is the same for any number of workers
+func growForest(p treeParams, rows [][]float64, y []float64, numClasses, numTrees int, bootstrap bool, seed int64, workers int) *forest {
+	rnd := rand.New(rand.NewSource(seed))
+	seeds := make([]int64, numTrees)
+	for i := range seeds {
+		seeds[i] = rnd.Int63()
+	}
+
+	f := &forest{
+		trees:       make([]*treeNode, numTrees),
+		inBag:       make([][]bool, numTrees),
+		numFeatures: len(rows[0]),
+	}
+	importances := make([][]float64, numTrees)
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				treeRnd := rand.New(rand.NewSource(seeds[i]))
+
+				idx := allRows(len(rows))
+				if bootstrap {
+					for k := range idx {
+						idx[k] = treeRnd.Intn(len(rows))
+					}
+				}
+
+				inBag := make([]bool, len(rows))
+				for _, k := range idx {
+					inBag[k] = true
+				}
+
+				b := newTreeBuilder(p, rows, y, numClasses, treeRnd)
+				f.trees[i], f.inBag[i] = b.build(idx, 0), inBag
+				importances[i] = normalizeImportances(b.importances)
+			}
+		}()
+	}
+
+	for i := 0; i < numTrees; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	//mean of the importances of all trees
+	f.importances = make([]float64, f.numFeatures)
+	for _, imp := range importances {
+		for j, v := range imp {
+			f.importances[j] += v / float64(numTrees)
+		}
+	}
+	return f
+}
+
+//average the leaf values of every tree for row
+//if oob is set only trees which didn't train on row i of the training data are used
+//return nil if no tree is used
+func (f *forest) average(row []float64, oob int) []float64 {
+	var result []float64
+	count := 0
+	for t, tree := range f.trees {
+		if oob >= 0 && f.inBag[t][oob] {
+			continue
+		}
+
+		value := tree.apply(row).value
+		if result == nil {
+			result = make([]float64, len(value))
+		}
+		for j, v := range value {
+			result[j] += v
+		}
+		count++
+	}
+
+	for j := range result {
+		result[j] /= float64(count)
+	}
+	return result
+}
+
+//average the out-of-bag predictions of all training rows
+//rows which were in the sample of every tree are skipped
+//return the predictions, the indices of the predicted rows and an error if there are none
+func (f *forest) oobPredictions(rows [][]float64) ([][]float64, []int, error) {
+	var values [][]float64
+	var idx []int
+	for i, row := range rows {
+		if v := f.average(row, i); v != nil {
+			values = append(values, v)
+			idx = append(idx, i)
+		}
+	}
+
+	if len(idx) == 0 {
+		return nil, nil, fmt.Errorf("Every row is in the sample of every tree, use more trees for an out-of-bag score")
+	}
+	return values, idx, nil
+}
+
+func validateForest(numTrees int) error {
+	if numTrees < 1 {
+		return fmt.Errorf("Number of trees should be at least 1")
+	}
+	return nil
+}
+
+///////////////////////////
+////////CLASSIFIER////////
+//////////////////////////
+
+func (rf *RandomForestClassifier) params(numFeatures int) treeParams {
+	maxFeatures := rf.MaxFeatures
+	if maxFeatures == 0 {
+		maxFeatures = maxInt(1, int(math.Sqrt(float64(numFeatures))))
+	}
+	return treeParams{rf.Criterion, rf.MaxDepth, rf.MinSamplesSplit, rf.MinSamplesLeaf, maxFeatures}
+}
+
+func (rf *RandomForestClassifier) Fit(x *Matrix, y *Vector) error {
+	if err := validateForest(rf.NumTrees); err != nil {
+		return err
+	}
+
+	if err := validateTreeInput(x, y); err != nil {
+		return err
+	}
+
+	p := rf.params(x.GetColumnNumber())
+	if err := p.validate(true); err != nil {
+		return err
+	}
+
+	//the model is only replaced once the out-of-bag score succeeded too
+	rows := x.rowSlices()
+	classes := uniqueSorted(y.val)
+	forest := growForest(p, rows, classIndexOf(y.val, classes), len(classes),
+		rf.NumTrees, rf.Bootstrap, rf.Seed, rf.Workers)
+
+	var oobScore float64
+	if rf.OOBScore && rf.Bootstrap {
+		proba, idx, err := forest.oobPredictions(rows)
+		if err != nil {
+			return err
+		}
+
+		correct := 0
+		for k, i := range idx {
+			if classes[argmax(proba[k])] == y.val[i] {
+				correct++
+			}
+		}
+		oobScore = float64(correct) / float64(len(idx))
+	}
+
+	rf.classes, rf.forest, rf.oobScore = classes, forest, oobScore
+	return nil
+}
+
+//get the accuracy of the out-of-bag predictions of the training rows
+//it is only available when the forest was trained with Bootstrap and OOBScore
+func (rf *RandomForestClassifier) GetOOBScore() (float64, error) {
+	if rf.forest == nil {
+		return 0, ErrNotFitted
+	}
+
+	if !rf.OOBScore || !rf.Bootstrap {
+		return 0, fmt.Errorf("Out-of-bag score needs Bootstrap and OOBScore")
+	}
+	return rf.oobScore, nil
+}
+
+//get the sorted labels seen by Fit
+func (rf *RandomForestClassifier) Classes() []float64 { return copyFloats(rf.classes) }
+
+//calculate the mean class probabilities of all trees
+//column j of the result belongs to Classes()[j-1]
+func (rf *RandomForestClassifier) PredictProba(x *Matrix) (*Matrix, error) {
+	if rf.forest == nil {
+		return nil, ErrNotFitted
+	}
+
+	if err := validateTreePredictInput(rf.forest.trees[0], rf.forest.numFeatures, x); err != nil {
+		return nil, err
+	}
+
+	rows := x.rowSlices()
+	input := make([][]float64, len(rows))
+	parallelRows(len(rows), len(rf.forest.trees)*rf.forest.numFeatures, func(from, to int) {
+		for i := from; i < to; i++ {
+			input[i] = rf.forest.average(rows[i], -1)
+		}
+	})
+	return NewMatrix(input)
+}
+
+//predict the class with the greatest mean probability
+func (rf *RandomForestClassifier) Predict(x *Matrix) (*Vector, error) {
+	proba, err := rf.PredictProba(x)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewZeroVector(proba.GetRowNumber())
+	for i := 1; i <= proba.GetRowNumber(); i++ {
+		result.val[i-1] = rf.classes[argmax(proba.val[i].val)]
+	}
+	return result, nil
+}
+
+//get the mean of the feature importances of all trees
+func (rf *RandomForestClassifier) FeatureImportances() *Vector {
+	if rf.forest == nil {
+		return nil
+	}
+	return NewVector(copyFloats(rf.forest.importances))
+}
+
+///////////////////////////
+////////REGRESSOR/////////
+//////////////////////////
+
+func (rf *RandomForestRegressor) params(numFeatures int) treeParams {
+	maxFeatures := rf.MaxFeatures
+	if maxFeatures == 0 {
+		maxFeatures = maxInt(1, numFeatures/3)
+	}
+	return treeParams{rf.Criterion, rf.MaxDepth, rf.MinSamplesSplit, rf.MinSamplesLeaf, maxFeatures}
+}
+
+func (rf *RandomForestRegressor) Fit(x *Matrix, y *Vector) error {
+	if err := validateForest(rf.NumTrees); err != nil {
+		return err
+	}
+
+	if err := validateTreeInput(x, y); err != nil {
+		return err
+	}
+
+	p := rf.params(x.GetColumnNumber())
+	if err := p.validate(false); err != nil {
+		return err
+	}
+
+	//the model is only replaced once the out-of-bag score succeeded too
+	rows := x.rowSlices()
+	forest := growForest(p, rows, y.val, 0, rf.NumTrees, rf.Bootstrap, rf.Seed, rf.Workers)
+
+	var oobScore float64
+	if rf.OOBScore && rf.Bootstrap {
+		values, idx, err := forest.oobPredictions(rows)
+		if err != nil {
+			return err
+		}
+
+		predicted, actual := NewZeroVector(len(idx)), NewZeroVector(len(idx))
+		for k, i := range idx {
+			predicted.val[k], actual.val[k] = values[k][0], y.val[i]
+		}
+
+		if oobScore, err = R2Score(predicted, actual); err != nil {
+			return err
+		}
+	}
+
+	rf.forest, rf.oobScore = forest, oobScore
+	return nil
+}
+
+//get the R^2 score of the out-of-bag predictions of the training rows
+//it is only available when the forest was trained with Bootstrap and OOBScore
+func (rf *RandomForestRegressor) GetOOBScore() (float64, error) {
+	if rf.forest == nil {
+		return 0, ErrNotFitted
+	}
+
+	if !rf.OOBScore || !rf.Bootstrap {
+		return 0, fmt.Errorf("Out-of-bag score needs Bootstrap and OOBScore")
+	}
+	return rf.oobScore, nil
+}
+
+//predict the mean prediction of all trees
+func (rf *RandomForestRegressor) Predict(x *Matrix) (*Vector, error) {
+	if rf.forest == nil {
+		return nil, ErrNotFitted
+	}
+
+	if err := validateTreePredictInput(rf.forest.trees[0], rf.forest.numFeatures, x); err != nil {
+		return nil, err
+	}
+
+	rows := x.rowSlices()
+	result := NewZeroVector(len(rows))
+	parallelRows(len(rows), len(rf.forest.trees)*rf.forest.numFeatures, func(from, to int) {
+		for i := from; i < to; i++ {
+			result.val[i] = rf.forest.average(rows[i], -1)[0]
+		}
+	})
+	return result, nil
+}
+
+//get the mean of the feature importances of all trees
+func (rf *RandomForestRegressor) FeatureImportances() *Vector {
+	if rf.forest == nil {
+		return nil
+	}
+	return NewVector(copyFloats(rf.forest.importances))
+}
@@ -0,0 +1,143 @@
+package ml
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//add a column of random noise to x
+func addNoiseColumn(x *Matrix, seed int64) *Matrix {
+	rnd := rand.New(rand.NewSource(seed))
+	noise := NewZeroVector(x.GetRowNumber())
+	for i := range noise.val {
+		noise.val[i] = rnd.Float64()
+	}
+
+	result := x.Clone()
+	result.AddColumnVector(noise)
+	return result
+}
+
+func TestRandomForestClassifier(t *testing.T) {
+	_, err := NewRandomForestClassifier(0, 1)
+	assert.Error(t, err)
+
+	rf, _ := NewRandomForestClassifier(50, 1)
+	_, err = rf.Predict(NewConstantMatrix(1, 2, 0))
+	assert.Equal(t, ErrNotFitted, err)
+	_, err = rf.GetOOBScore()
+	assert.Equal(t, ErrNotFitted, err)
+
+	x, _ := LoadNewMatrix("data2.csv", ":", "1:2")
+	y, _ := LoadNewVector("data2.csv", ":", "3")
+	x = addNoiseColumn(x, 1)
+
+	rf.MinSamplesLeaf = 3
+	assert.NoError(t, rf.Fit(x, y))
+
+	oob, err := rf.GetOOBScore()
+	assert.NoError(t, err)
+	fmt.Printf("Random forest out-of-bag accuracy on data2.csv: %v\n", oob)
+	assert.True(t, oob > 0.7)
+
+	//the noise column is the least important one
+	importances := rf.FeatureImportances()
+	fmt.Printf("Random forest feature importances: %v\n", importances)
+	assert.InDelta(t, 1, importances.val[0]+importances.val[1]+importances.val[2], 1e-9)
+	assert.True(t, importances.val[2] < importances.val[0])
+	assert.True(t, importances.val[2] < importances.val[1])
+
+	proba, err := rf.PredictProba(x)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, proba.val[1].val[0]+proba.val[1].val[1], 1e-9)
+
+	result, _ := rf.Predict(x)
+	acc, _ := Accuracy(result, y)
+	assert.True(t, acc > 0.85)
+
+	//a failing out-of-bag score keeps the previous model
+	//a single row is in the sample of every tree
+	single, _ := NewRandomForestClassifier(1, 1)
+	assert.Error(t, single.Fit(NewConstantMatrix(1, 2, 0), NewVector([]float64{1})))
+	_, err = single.Predict(NewConstantMatrix(1, 2, 0))
+	assert.Equal(t, ErrNotFitted, err)
+
+	assert.Error(t, rf.Fit(NewConstantMatrix(1, 3, 0), NewVector([]float64{5})))
+	assert.Equal(t, []float64{0, 1}, rf.Classes())
+	after, err := rf.GetOOBScore()
+	assert.NoError(t, err)
+	assert.Equal(t, oob, after)
+	result2, _ := rf.Predict(x)
+	assert.Equal(t, result.val, result2.val)
+
+	//without bootstrap there is no out-of-bag score
+	rf.Bootstrap = false
+	rf.Fit(x, y)
+	_, err = rf.GetOOBScore()
+	assert.Error(t, err)
+}
+
+func TestRandomForestDeterministic(t *testing.T) {
+	x, _ := LoadNewMatrix("data1.csv", ":", "1:2")
+	y, _ := LoadNewVector("data1.csv", ":", "3")
+
+	//the same seed gives the same forest for any number of workers
+	var results []*Vector
+	var scores []float64
+	for _, workers := range []int{1, 4} {
+		rf, _ := NewRandomForestClassifier(20, 7)
+		rf.Workers = workers
+		rf.Fit(x, y)
+
+		proba, _ := rf.PredictProba(x)
+		results = append(results, NewVector(proba.getColumnVector(1).val))
+		oob, _ := rf.GetOOBScore()
+		scores = append(scores, oob)
+	}
+	assert.Equal(t, results[0], results[1])
+	assert.Equal(t, scores[0], scores[1])
+
+	//other seeds give other forests
+	rf, _ := NewRandomForestClassifier(20, 8)
+	rf.Fit(x, y)
+	proba, _ := rf.PredictProba(x)
+	assert.NotEqual(t, results[0], NewVector(proba.getColumnVector(1).val))
+}
+
+func TestRandomForestRegressor(t *testing.T) {
+	xTrain, xTest, yTrain, yTest, _ := SequentialTrainTestSplit(loadData3(), loadData3Y(), 0.8)
+
+	rf, _ := NewRandomForestRegressor(30, 1)
+	rf.MinSamplesLeaf = 5
+	assert.NoError(t, rf.Fit(xTrain, yTrain))
+
+	oob, err := rf.GetOOBScore()
+	assert.NoError(t, err)
+
+	result, err := rf.Predict(xTest)
+	assert.NoError(t, err)
+	mae, _ := MeanAbsoluteError(result, yTest)
+	fmt.Printf("Random forest out-of-bag R^2 on data3.csv: %v, mean absolute error: %v\n", oob, mae)
+	assert.True(t, oob > 0.5)
+	assert.True(t, mae < 5)
+
+	assert.InDeltaSlice(t, []float64{1}, rf.FeatureImportances().val, 1e-12)
+
+	_, err = rf.Predict(NewConstantMatrix(1, 2, 0))
+	assert.Error(t, err)
+
+	//a failing out-of-bag score keeps the previous model
+	assert.Error(t, rf.Fit(NewConstantMatrix(1, 1, 0), NewVector([]float64{1})))
+	after, _ := rf.GetOOBScore()
+	assert.Equal(t, oob, after)
+	result2, _ := rf.Predict(xTest)
+	assert.Equal(t, result.val, result2.val)
+
+	//a single tree without bootstrap can't have out-of-bag rows
+	rf.NumTrees = 1
+	rf.Bootstrap = false
+	assert.NoError(t, rf.Fit(xTrain, yTrain))
+}
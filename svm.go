@@ -0,0 +1,499 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type (
+	//KernelType selects the kernel function of KernelSVM
+	KernelType int
+
+	//Kernel computes the inner product of two rows in the feature space
+	//Gamma 0 means 1 / number of columns, Degree and Coef0 are used by the polynomial
+	//and sigmoid kernels
+	Kernel struct {
+		Type   KernelType
+		Gamma  float64
+		Degree int
+		Coef0  float64
+	}
+
+	//LinearSVM minimizes the regularized hinge loss with the pegasos algorithm
+	//every epoch visits all rows in random order with step size 1 / (Lambda * t)
+	//the bias is learned as the weight of a constant 1's column, so it is regularized as well
+	LinearSVM struct {
+		Lambda float64
+		Epochs int
+		Seed   int64
+
+		theta []float64
+	}
+
+	//KernelSVM solves the dual problem of the soft margin SVM with SMO
+	//C is the penalty of margin violations, the training stops when the
+	//maximal violating pair differs by less than Tol or after MaxIter iterations
+	//the kernel matrix is precomputed, so memory grows with the square of the number of rows
+	KernelSVM struct {
+		C       float64
+		Kernel  Kernel
+		Tol     float64
+		MaxIter int
+
+		kernel  Kernel
+		svs     [][]float64
+		coef    []float64
+		rho     float64
+		nIter   int
+		numFeat int
+	}
+)
+
+const (
+	//x . z
+	LinearKernel KernelType = iota
+	//(gamma * x . z + coef0)^degree
+	PolynomialKernel
+	//exp(-gamma * |x - z|^2)
+	RBFKernel
+	//tanh(gamma * x . z + coef0)
+	SigmoidKernel
+)
+
+func (k KernelType) String() string {
+	switch k {
+	case LinearKernel:
+		return "linear"
+	case PolynomialKernel:
+		return "polynomial"
+	case RBFKernel:
+		return "rbf"
+	case SigmoidKernel:
+		return "sigmoid"
+	}
+	return fmt.Sprintf("KernelType(%d)", int(k))
+}
+
+func (k Kernel) validate() error {
+	if k.Type < LinearKernel || k.Type > SigmoidKernel {
+		return fmt.Errorf("Unknown kernel %v", k.Type)
+	}
+
+	if k.Gamma < 0 {
+		return fmt.Errorf("Gamma should not be negative")
+	}
+
+	if k.Type == PolynomialKernel && k.Degree < 1 {
+		return fmt.Errorf("Degree of the polynomial kernel should be at least 1")
+	}
+
+	return nil
+}
+
+func (k Kernel) compute(a, b []float64) float64 {
+	switch k.Type {
+	case PolynomialKernel:
+		return math.Pow(k.Gamma*dotFloats(a, b)+k.Coef0, float64(k.Degree))
+	case RBFKernel:
+		return math.Exp(-k.Gamma * squaredDistance(a, b))
+	case SigmoidKernel:
+		return math.Tanh(k.Gamma*dotFloats(a, b) + k.Coef0)
+	}
+	return dotFloats(a, b)
+}
+
+func dotFloats(a, b []float64) float64 {
+	var result float64
+	for i := range a {
+		result += a[i] * b[i]
+	}
+	return result
+}
+
+//map the labels 0 and 1 to -1 and 1
+func svmLabels(y *Vector) ([]float64, error) {
+	if err := validateBinaryLabels(y); err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, y.GetLength())
+	for i, label := range y.val {
+		result[i] = 2*label - 1
+	}
+	return result, nil
+}
+
+//predict 1 for a non negative decision value and 0 otherwise
+func svmPredict(decision *Vector) *Vector {
+	return decision.Map(func(f float64) float64 {
+		if f < 0 {
+			return 0
+		}
+		return 1
+	})
+}
+
+///////////////////////////
+////////LINEAR////////////
+//////////////////////////
+
+//lambda should be greater than 0, a greater lambda means a wider margin with more violations
+func NewLinearSVM(lambda float64, epochs int, seed int64) (*LinearSVM, error) {
+	svm := &LinearSVM{Lambda: lambda, Epochs: epochs, Seed: seed}
+	if err := svm.validate(); err != nil {
+		return nil, err
+	}
+	return svm, nil
+}
+
+func (svm *LinearSVM) validate() error {
+	if svm.Lambda <= 0 {
+		return fmt.Errorf("Lambda should be greater than 0")
+	}
+
+	if svm.Epochs < 1 {
+		return fmt.Errorf("Number of epochs should be at least 1")
+	}
+
+	return nil
+}
+
+//labels should be 0 or 1
+func (svm *LinearSVM) Fit(x *Matrix, y *Vector) error {
+	if err := svm.validate(); err != nil {
+		return err
+	}
+
+	if err := validateTreeInput(x, y); err != nil {
+		return err
+	}
+
+	labels, err := svmLabels(y)
+	if err != nil {
+		return err
+	}
+
+	//the 1's column for the bias
+	rows := x.rowSlices()
+	for i, row := range rows {
+		rows[i] = append([]float64{1}, row...)
+	}
+
+	rnd := rand.New(rand.NewSource(svm.Seed))
+	theta := make([]float64, len(rows[0]))
+	radius := 1 / math.Sqrt(svm.Lambda)
+
+	t := 0
+	for e := 0; e < svm.Epochs; e++ {
+		for _, i := range rnd.Perm(len(rows)) {
+			t++
+			eta := 1 / (svm.Lambda * float64(t))
+			margin := labels[i] * dotFloats(theta, rows[i])
+
+			for j := range theta {
+				theta[j] *= 1 - eta*svm.Lambda
+			}
+			if margin < 1 {
+				for j, f := range rows[i] {
+					theta[j] += eta * labels[i] * f
+				}
+			}
+
+			//the optimum lies within the ball of radius 1 / sqrt(lambda)
+			if norm := math.Sqrt(dotFloats(theta, theta)); norm > radius {
+				for j := range theta {
+					theta[j] *= radius / norm
+				}
+			}
+		}
+	}
+
+	svm.theta = theta
+	return nil
+}
+
+//get the weights with the bias as first element, the same layout as the theta of LReg
+func (svm *LinearSVM) Theta() *Vector {
+	return NewVector(copyFloats(svm.theta))
+}
+
+//calculate theta . [1, x] for every row, the sign is the predicted side of the margin
+func (svm *LinearSVM) DecisionFunction(x *Matrix) (*Vector, error) {
+	if svm.theta == nil {
+		return nil, ErrNotFitted
+	}
+
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+
+	if x.GetColumnNumber()+1 != len(svm.theta) {
+		return nil, fmt.Errorf("Input dimension(%d) does not agree with training data(%d)",
+			x.GetColumnNumber(), len(svm.theta)-1)
+	}
+
+	rows := x.rowSlices()
+	result := NewZeroVector(len(rows))
+	for i, row := range rows {
+		result.val[i] = svm.theta[0] + dotFloats(svm.theta[1:], row)
+	}
+	return result, nil
+}
+
+//predict 1 for rows on the positive side of the margin and 0 otherwise
+func (svm *LinearSVM) Predict(x *Matrix) (*Vector, error) {
+	decision, err := svm.DecisionFunction(x)
+	if err != nil {
+		return nil, err
+	}
+	return svmPredict(decision), nil
+}
+
+///////////////////////////
+////////KERNEL////////////
+//////////////////////////
+
+//create a kernel svm with tolerance 1e-3 and at most 100000 iterations
+func NewKernelSVM(c float64, kernel Kernel) (*KernelSVM, error) {
+	svm := &KernelSVM{C: c, Kernel: kernel, Tol: 1e-3, MaxIter: 100000}
+	if err := svm.validate(); err != nil {
+		return nil, err
+	}
+	return svm, nil
+}
+
+func (svm *KernelSVM) validate() error {
+	if svm.C <= 0 {
+		return fmt.Errorf("C should be greater than 0")
+	}
+
+	if svm.Tol <= 0 {
+		return fmt.Errorf("Tol should be greater than 0")
+	}
+
+	if svm.MaxIter < 1 {
+		return fmt.Errorf("MaxIter should be at least 1")
+	}
+
+	return svm.Kernel.validate()
+}
+
+//solve the dual with the maximal violating pair as working set
+//labels should be 0 or 1
+func (svm *KernelSVM) Fit(x *Matrix, y *Vector) error {
+	if err := svm.validate(); err != nil {
+		return err
+	}
+
+	if err := validateTreeInput(x, y); err != nil {
+		return err
+	}
+
+	labels, err := svmLabels(y)
+	if err != nil {
+		return err
+	}
+
+	if len(uniqueSorted(labels)) != 2 {
+		return fmt.Errorf("Both labels 0 and 1 are needed")
+	}
+
+	kernel := svm.Kernel
+	if kernel.Gamma == 0 {
+		kernel.Gamma = 1 / float64(x.GetColumnNumber())
+	}
+
+	rows := x.rowSlices()
+	n := len(rows)
+
+	//q[i][j] = yi * yj * K(xi, xj)
+	q := make([][]float64, n)
+	parallelRows(n, n*len(rows[0]), func(from, to int) {
+		for i := from; i < to; i++ {
+			q[i] = make([]float64, n)
+			for j := range rows {
+				q[i][j] = labels[i] * labels[j] * kernel.compute(rows[i], rows[j])
+			}
+		}
+	})
+
+	alpha := make([]float64, n)
+	grad := make([]float64, n)
+	for i := range grad {
+		grad[i] = -1
+	}
+
+	c := svm.C
+	itr := 0
+	for itr < svm.MaxIter {
+		i, j, gap := svm.selectPair(alpha, grad, labels)
+		if gap < svm.Tol {
+			break
+		}
+		itr++
+
+		oldI, oldJ := alpha[i], alpha[j]
+		if labels[i] != labels[j] {
+			quad := math.Max(q[i][i]+q[j][j]+2*q[i][j], 1e-12)
+			delta := (-grad[i] - grad[j]) / quad
+			diff := alpha[i] - alpha[j]
+			alpha[i] += delta
+			alpha[j] += delta
+
+			if diff > 0 && alpha[j] < 0 {
+				alpha[j], alpha[i] = 0, diff
+			} else if diff <= 0 && alpha[i] < 0 {
+				alpha[i], alpha[j] = 0, -diff
+			}
+			if diff > 0 && alpha[i] > c {
+				alpha[i], alpha[j] = c, c-diff
+			} else if diff <= 0 && alpha[j] > c {
+				alpha[j], alpha[i] = c, c+diff
+			}
+		} else {
+			quad := math.Max(q[i][i]+q[j][j]-2*q[i][j], 1e-12)
+			delta := (grad[i] - grad[j]) / quad
+			sum := alpha[i] + alpha[j]
+			alpha[i] -= delta
+			alpha[j] += delta
+
+			if sum > c && alpha[i] > c {
+				alpha[i], alpha[j] = c, sum-c
+			} else if sum <= c && alpha[j] < 0 {
+				alpha[j], alpha[i] = 0, sum
+			}
+			if sum > c && alpha[j] > c {
+				alpha[j], alpha[i] = c, sum-c
+			} else if sum <= c && alpha[i] < 0 {
+				alpha[i], alpha[j] = 0, sum
+			}
+		}
+
+		dI, dJ := alpha[i]-oldI, alpha[j]-oldJ
+		for k := range grad {
+			grad[k] += q[i][k]*dI + q[j][k]*dJ
+		}
+	}
+
+	svm.kernel, svm.nIter, svm.numFeat = kernel, itr, x.GetColumnNumber()
+	svm.rho = svm.calculateRho(alpha, grad, labels)
+
+	//only rows with alpha greater than 0 are needed for prediction
+	svm.svs, svm.coef = nil, nil
+	for i, a := range alpha {
+		if a > 0 {
+			svm.svs = append(svm.svs, copyFloats(rows[i]))
+			svm.coef = append(svm.coef, a*labels[i])
+		}
+	}
+	return nil
+}
+
+//select i with the greatest and j with the least -y * grad among the rows which can still move
+//return both and the difference of their values
+func (svm *KernelSVM) selectPair(alpha, grad, labels []float64) (int, int, float64) {
+	i, j := -1, -1
+	max, min := math.Inf(-1), math.Inf(1)
+	for t := range alpha {
+		v := -labels[t] * grad[t]
+		up := (labels[t] > 0 && alpha[t] < svm.C) || (labels[t] < 0 && alpha[t] > 0)
+		low := (labels[t] < 0 && alpha[t] < svm.C) || (labels[t] > 0 && alpha[t] > 0)
+
+		if up && v > max {
+			i, max = t, v
+		}
+		if low && v < min {
+			j, min = t, v
+		}
+	}
+
+	if i < 0 || j < 0 {
+		return 0, 0, 0
+	}
+	return i, j, max - min
+}
+
+//the bias is the mean of y * grad of the free rows (0 < alpha < C)
+//or the middle of its feasible range if there are none
+func (svm *KernelSVM) calculateRho(alpha, grad, labels []float64) float64 {
+	ub, lb := math.Inf(1), math.Inf(-1)
+	var sum float64
+	free := 0
+	for i, a := range alpha {
+		yg := labels[i] * grad[i]
+		switch {
+		case a >= svm.C:
+			if labels[i] < 0 {
+				ub = math.Min(ub, yg)
+			} else {
+				lb = math.Max(lb, yg)
+			}
+		case a <= 0:
+			if labels[i] > 0 {
+				ub = math.Min(ub, yg)
+			} else {
+				lb = math.Max(lb, yg)
+			}
+		default:
+			free++
+			sum += yg
+		}
+	}
+
+	if free > 0 {
+		return sum / float64(free)
+	}
+	return (ub + lb) / 2
+}
+
+//calculate sigma(alpha_i * y_i * K(sv_i, x)) - rho for every row
+func (svm *KernelSVM) DecisionFunction(x *Matrix) (*Vector, error) {
+	if svm.coef == nil {
+		return nil, ErrNotFitted
+	}
+
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+
+	if x.GetColumnNumber() != svm.numFeat {
+		return nil, fmt.Errorf("Input dimension(%d) does not agree with training data(%d)",
+			x.GetColumnNumber(), svm.numFeat)
+	}
+
+	rows := x.rowSlices()
+	result := NewZeroVector(len(rows))
+	parallelRows(len(rows), len(svm.svs)*svm.numFeat, func(from, to int) {
+		for i := from; i < to; i++ {
+			var f float64
+			for k, sv := range svm.svs {
+				f += svm.coef[k] * svm.kernel.compute(sv, rows[i])
+			}
+			result.val[i] = f - svm.rho
+		}
+	})
+	return result, nil
+}
+
+//predict 1 for rows on the positive side of the margin and 0 otherwise
+func (svm *KernelSVM) Predict(x *Matrix) (*Vector, error) {
+	decision, err := svm.DecisionFunction(x)
+	if err != nil {
+		return nil, err
+	}
+	return svmPredict(decision), nil
+}
+
+//get the training rows with alpha greater than 0
+func (svm *KernelSVM) SupportVectors() (*Matrix, error) {
+	if svm.coef == nil {
+		return nil, ErrNotFitted
+	}
+	return NewMatrix(cloneRows(svm.svs))
+}
+
+func (svm *KernelSVM) GetNumSupportVectors() int { return len(svm.svs) }
+
+//get the number of SMO iterations of the last Fit
+func (svm *KernelSVM) GetIterations() int { return svm.nIter }
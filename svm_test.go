@@ -0,0 +1,134 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKernel(t *testing.T) {
+	a, b := []float64{1, 2}, []float64{3, 1}
+
+	assert.Equal(t, float64(5), Kernel{Type: LinearKernel}.compute(a, b))
+	assert.Equal(t, float64(36), Kernel{Type: PolynomialKernel, Gamma: 1, Degree: 2, Coef0: 1}.compute(a, b))
+	assert.InDelta(t, math.Exp(-2.5), Kernel{Type: RBFKernel, Gamma: 0.5}.compute(a, b), 1e-12)
+	assert.InDelta(t, math.Tanh(0.5), Kernel{Type: SigmoidKernel, Gamma: 0.1, Coef0: 0}.compute(a, b), 1e-12)
+
+	assert.Error(t, Kernel{Type: PolynomialKernel}.validate())
+	assert.Error(t, Kernel{Type: KernelType(9)}.validate())
+	assert.Error(t, Kernel{Type: RBFKernel, Gamma: -1}.validate())
+}
+
+//data1.csv with both exam scores scaled into [0, 1]
+func loadScaledData1() (*Matrix, *Vector) {
+	x, _ := LoadNewMatrix("data1.csv", ":", "1:2")
+	y, _ := LoadNewVector("data1.csv", ":", "3")
+	x.MultiplyVariable(0.01)
+	return x, y
+}
+
+func TestLinearSVM(t *testing.T) {
+	_, err := NewLinearSVM(0, 10, 1)
+	assert.Error(t, err)
+
+	svm, _ := NewLinearSVM(0.001, 200, 1)
+	_, err = svm.Predict(NewConstantMatrix(1, 2, 0))
+	assert.Equal(t, ErrNotFitted, err)
+
+	x, y := loadScaledData1()
+	assert.Error(t, svm.Fit(x, NewConstantVector(y.GetLength(), -1)))
+	assert.NoError(t, svm.Fit(x, y))
+
+	result, err := svm.Predict(x)
+	assert.NoError(t, err)
+	acc, _ := Accuracy(result, y)
+	fmt.Printf("Linear SVM accuracy on data1.csv: %v, theta: %v\n", acc, svm.Theta())
+	assert.True(t, acc > 0.85)
+
+	//same as logistic regression both scores increase the chance of admission
+	theta := svm.Theta()
+	assert.True(t, theta.val[1] > 0)
+	assert.True(t, theta.val[2] > 0)
+
+	_, err = svm.Predict(NewConstantMatrix(1, 3, 0))
+	assert.Error(t, err)
+}
+
+func TestKernelSVM(t *testing.T) {
+	_, err := NewKernelSVM(0, Kernel{Type: RBFKernel})
+	assert.Error(t, err)
+
+	//xor needs a non-linear kernel
+	x, _ := NewMatrix([][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}})
+	y := NewVector([]float64{0, 1, 1, 0})
+
+	svm, _ := NewKernelSVM(10, Kernel{Type: RBFKernel, Gamma: 1})
+	_, err = svm.Predict(x)
+	assert.Equal(t, ErrNotFitted, err)
+	assert.Error(t, svm.Fit(x, NewVector([]float64{1, 1, 1, 1})))
+
+	assert.NoError(t, svm.Fit(x, y))
+	result, _ := svm.Predict(x)
+	assert.Equal(t, y.val, result.val)
+	assert.Equal(t, 4, svm.GetNumSupportVectors())
+
+	//the decision values have the margin 1 on the support vectors
+	decision, _ := svm.DecisionFunction(x)
+	for i, d := range decision.val {
+		assert.InDelta(t, 2*y.val[i]-1, d, 1e-2)
+	}
+
+	svm.Kernel = Kernel{Type: PolynomialKernel, Gamma: 1, Degree: 2, Coef0: 1}
+	svm.Fit(x, y)
+	result, _ = svm.Predict(x)
+	assert.Equal(t, y.val, result.val)
+}
+
+func TestKernelSVMLinearData(t *testing.T) {
+	x, y := loadScaledData1()
+
+	svm, _ := NewKernelSVM(10, Kernel{Type: LinearKernel})
+	assert.NoError(t, svm.Fit(x, y))
+
+	result, _ := svm.Predict(x)
+	acc, _ := Accuracy(result, y)
+	fmt.Printf("Linear kernel SVM accuracy on data1.csv: %v with %v support vectors after %v iterations\n",
+		acc, svm.GetNumSupportVectors(), svm.GetIterations())
+	assert.True(t, acc > 0.85)
+
+	svs, _ := svm.SupportVectors()
+	assert.Equal(t, svm.GetNumSupportVectors(), svs.GetRowNumber())
+}
+
+func TestKernelSVMFromExData(t *testing.T) {
+	//non-linear boundary of data2.csv without NewFeatureMatrix
+	x, _ := LoadNewMatrix("data2.csv", ":", "1:2")
+	y, _ := LoadNewVector("data2.csv", ":", "3")
+
+	folds, _ := NewStratifiedKFold(5, true, 1)
+	for _, kernel := range []Kernel{
+		{Type: RBFKernel, Gamma: 5},
+		{Type: PolynomialKernel, Gamma: 1, Degree: 2, Coef0: 1},
+	} {
+		result, err := CrossValidate(func() Estimator {
+			svm, _ := NewKernelSVM(1, kernel)
+			return svm
+		}, x, y, folds, Accuracy)
+		assert.NoError(t, err)
+		fmt.Printf("Kernel SVM (%v) accuracy on data2.csv: %v\n", kernel.Type, result.Mean)
+		assert.True(t, result.Mean > 0.75)
+	}
+
+	//a linear boundary can't separate data2.csv
+	svm, _ := NewKernelSVM(1, Kernel{Type: LinearKernel})
+	svm.Fit(x, y)
+	result, _ := svm.Predict(x)
+	acc, _ := Accuracy(result, y)
+	fmt.Printf("Linear kernel SVM accuracy on data2.csv: %v\n", acc)
+	assert.True(t, acc < 0.7)
+
+	sigmoid, _ := NewKernelSVM(1, Kernel{Type: SigmoidKernel, Gamma: 0.5})
+	assert.NoError(t, sigmoid.Fit(x, y))
+}
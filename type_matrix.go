@@ -4,6 +4,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"strings"
 )
 
@@ -11,6 +12,11 @@ type (
 	//matrix key is the column number
 	//and each key's value is the value
 	//but actually better use the method getVal
+	//a function which returns a *Matrix never modifies the receiver
+	//but returns a new matrix e.g. Apply, Exp, Clip or Transpose
+	//a function which returns nothing or only an error modifies the
+	//receiver in place e.g. Calculate, SubtractMatrix or BroadcastRowVector
+	//a view returned by View or ViewRows shares its rows with the receiver
 	Matrix struct {
 		val map[int]*Vector
 	}
@@ -102,18 +108,10 @@ func NewFeatureMatrix(v1, v2 *Vector, numfeat uint) (*Matrix, error) {
 	for i := 1; i <= int(numfeat); i++ {
 		//also loop from 0 to i
 		for j := 0; j <= i; j++ {
-			//create new variable to avoid changing value by pointer
-			var v12, v22 []float64
-			v12 = append(v12, v1.val...)
-			v22 = append(v22, v2.val...)
-
 			//the new column vector is multiplication from v1^(i-j) and v2(j)
-			vec1, vec2 := NewVector(v12), NewVector(v22)
-			vec1.PowerOf(float64(i - j))
-			vec2.PowerOf(float64(j))
-			vec1.MultiplyVector(vec2)
-
-			m.addColumnVector(vec1)
+			//length of both is already validated
+			col, _ := v1.Pow(float64(i - j)).Mul(v2.Pow(float64(j)))
+			m.addColumnVector(col)
 		}
 	}
 	return m, nil
@@ -317,34 +315,47 @@ func (m *Matrix) GetAllColumnVectors() []*Vector {
 ////////CALCULATION///////
 //////////////////////////
 
+//get the row values as [][]float64 without copying them
+//index 0 is row 1, so modifying the result modifies the matrix
+func (m *Matrix) rowSlices() [][]float64 {
+	result := make([][]float64, m.GetRowNumber())
+	for i := range result {
+		result[i] = m.val[i+1].val
+	}
+	return result
+}
+
+//apply op to every element of the matrix, rows are processed in parallel
+func (m *Matrix) Calculate(op func(float64) float64) {
+	rows := m.rowSlices()
+	parallelRows(len(rows), m.GetColumnNumber(), func(from, to int) {
+		for _, row := range rows[from:to] {
+			for j, x := range row {
+				row[j] = op(x)
+			}
+		}
+	})
+}
+
 //add a variable into a matrix
 func (m *Matrix) AddVariable(x float64) {
-	for i := 1; i <= m.GetRowNumber(); i++ {
-		m.val[i].AddVariable(x)
-	}
+	m.Calculate(func(val float64) float64 { return val + x })
 }
 
 //multiply the matrix with a variable
 func (m *Matrix) MultiplyVariable(x float64) {
-	for i := 1; i <= m.GetRowNumber(); i++ {
-		m.val[i].MultiplyVariable(x)
-	}
+	m.Calculate(func(val float64) float64 { return val * x })
 }
 
 //add two Matrizes
 //as validation: dimension of both should agree
 //simply add the values from both with the same index
 func (m *Matrix) addMatrix(m2 *Matrix) {
-	for i := 1; i <= m.GetColumnNumber(); i++ {
-		for j := 1; j <= m.GetRowNumber(); j++ {
-			newVal := m.getSingleValue(j, i) + m2.getSingleValue(j, i)
-			m.setSingleValue(j, i, newVal)
-		}
-	}
+	m.calculateMatrix(m2, func(a, b float64) float64 { return a + b })
 }
 
-func (m *Matrix) AddMatrix(m2 *Matrix) error {
-	//make sure both of the dimensions agree
+//make sure both of the dimensions agree
+func (m *Matrix) validateSameDimension(m2 *Matrix) error {
 	if m.GetRowNumber() != m2.GetRowNumber() {
 		return fmt.Errorf("Row number does not agree")
 	}
@@ -352,28 +363,56 @@ func (m *Matrix) AddMatrix(m2 *Matrix) error {
 		return fmt.Errorf("Column number does not agree")
 	}
 
+	return nil
+}
+
+func (m *Matrix) AddMatrix(m2 *Matrix) error {
+	if err := m.validateSameDimension(m2); err != nil {
+		return err
+	}
+
 	m.addMatrix(m2)
 	return nil
 }
 
+//size of the square blocks used by the matrix multiplication
+const multiplyBlockSize = 64
+
 //Matrix multiplication
 //the order between m and m2 matters
 //number of m's col and m2's row must agree
+//rows of the result are computed in parallel, each worker walks through
+//blocks of m2 so the rows it needs stay in the cache
 func (m *Matrix) multiply(m2 *Matrix) *Matrix {
-	//create new matrix with row = m's row and column = m2's column
-	res := NewZeroMatrix(m.GetRowNumber(), m2.GetColumnNumber())
-
-	for i := 1; i <= m.GetRowNumber(); i++ {
-		for j := 1; j <= m.GetColumnNumber(); j++ {
-			//create dot product between row and col vectors
-			rowVec := m.getRowVector(i)
-			colVec := m2.getColumnVector(j)
-			dp := rowVec.dotProduct(colVec)
-			res.setSingleValue(i, j, dp)
+	a, b := m.rowSlices(), m2.rowSlices()
+	inner, numCol := len(b), len(b[0])
+
+	res := make([][]float64, len(a))
+	for i := range res {
+		res[i] = make([]float64, numCol)
+	}
+
+	parallelRows(len(a), inner*numCol, func(from, to int) {
+		for kk := 0; kk < inner; kk += multiplyBlockSize {
+			kEnd := minInt(kk+multiplyBlockSize, inner)
+			for jj := 0; jj < numCol; jj += multiplyBlockSize {
+				jEnd := minInt(jj+multiplyBlockSize, numCol)
+				for i := from; i < to; i++ {
+					ri, ai := res[i], a[i]
+					for k := kk; k < kEnd; k++ {
+						aik, bk := ai[k], b[k]
+						for j := jj; j < jEnd; j++ {
+							ri[j] += aik * bk[j]
+						}
+					}
+				}
+			}
 		}
-	}
+	})
 
-	return res
+	var result Matrix
+	result.setValue(res)
+	return &result
 }
 
 func (m *Matrix) Multiply(m2 *Matrix) (*Matrix, error) {
@@ -453,3 +492,377 @@ func (m *Matrix) AddConstantVectorToFirst(cons float64) {
 		m.val[key] = NewVector(newx)
 	}
 }
+
+///////////////////////////
+////////SELECTION/////////
+//////////////////////////
+
+//create a deep copy of a matrix
+//modifying the copy doesn't change the original matrix and vice versa
+func (m *Matrix) Clone() *Matrix {
+	res := map[int]*Vector{}
+	for key, row := range m.val {
+		res[key] = row.Clone()
+	}
+
+	return &Matrix{val: res}
+}
+
+//select the given rows and columns (both 1-based) into a new matrix
+//if share is true the row vectors are not copied, so the result is a view of m
+//the indices should already be validated
+func (m *Matrix) selectValue(rows, cols []int, share bool) *Matrix {
+	res := map[int]*Vector{}
+	for key, r := range rows {
+		row := m.getRowVector(r)
+		switch {
+		case share:
+			res[key+1] = row
+		case cols == nil:
+			res[key+1] = NewVector(copyFloats(row.val))
+		default:
+			f := make([]float64, 0, len(cols))
+			for _, c := range cols {
+				f = append(f, row.getSingleValue(c))
+			}
+			res[key+1] = NewVector(f)
+		}
+	}
+
+	return &Matrix{val: res}
+}
+
+//validate a list of indices against a dimension with n elements
+//negative indices are resolved from the end
+func resolveIndexList(idx []int, n int) ([]int, error) {
+	if len(idx) == 0 {
+		return nil, ErrEmptyMatrix
+	}
+
+	result := make([]int, 0, len(idx))
+	for _, i := range idx {
+		res, err := resolveIndex(i, n)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, res)
+	}
+
+	return result, nil
+}
+
+//validate that every resolved index is selected only once
+//e.g. -1 and n are the same index
+func validateUniqueIndex(idx []int) error {
+	seen := make(map[int]bool, len(idx))
+	for _, i := range idx {
+		if seen[i] {
+			return ErrDuplicateIndex
+		}
+		seen[i] = true
+	}
+	return nil
+}
+
+//select rows and columns using the same syntax as LoadNewMatrix
+//e.g. Slice("1:80", ":") or Slice("-20:", "1:2") or Slice("::2", "3")
+//the result is a copy of the selected values
+func (m *Matrix) Slice(row, col string) (*Matrix, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := parseIndexSpec(row, m.GetRowNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	cols, err := parseIndexSpec(col, m.GetColumnNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	return m.selectValue(rows, cols, false), nil
+}
+
+//select an arbitrary list of rows, indices may repeat and don't need to be sorted
+//the result is a copy of the selected rows
+func (m *Matrix) SelectRows(rows []int) (*Matrix, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := resolveIndexList(rows, m.GetRowNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	return m.selectValue(rows, nil, false), nil
+}
+
+//select an arbitrary list of columns, indices may repeat and don't need to be sorted
+//the result is a copy of the selected columns
+func (m *Matrix) SelectColumns(cols []int) (*Matrix, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	cols, err := resolveIndexList(cols, m.GetColumnNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _ := parseIndexSpec(":", m.GetRowNumber())
+	return m.selectValue(rows, cols, false), nil
+}
+
+//select all rows where mask is true
+//length of mask should be the same as number of rows
+//a mask which selects nothing returns ErrEmptyMatrix, same as Vector.Mask
+func (m *Matrix) MaskRows(mask []bool) (*Matrix, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	if len(mask) != m.GetRowNumber() {
+		return nil, fmt.Errorf("Mask length must be the same as number of rows")
+	}
+
+	var rows []int
+	for key, ok := range mask {
+		if ok {
+			rows = append(rows, key+1)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmptyMatrix
+	}
+
+	return m.selectValue(rows, nil, false), nil
+}
+
+//remove the given columns and return the remaining ones as a copy
+func (m *Matrix) DropColumns(cols ...int) (*Matrix, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	drop := map[int]bool{}
+	for _, c := range cols {
+		res, err := resolveIndex(c, m.GetColumnNumber())
+		if err != nil {
+			return nil, err
+		}
+		drop[res] = true
+	}
+
+	var keep []int
+	for i := 1; i <= m.GetColumnNumber(); i++ {
+		if !drop[i] {
+			keep = append(keep, i)
+		}
+	}
+
+	if len(keep) == 0 {
+		return nil, ErrEmptyMatrix
+	}
+
+	rows, _ := parseIndexSpec(":", m.GetRowNumber())
+	return m.selectValue(rows, keep, false), nil
+}
+
+//select rows using the row syntax of Slice but without copying them
+//the result shares its row vectors with m, so setting a value in one
+//of them is visible in the other
+//as validation a row can't be selected twice, otherwise in-place
+//functions would modify it twice
+func (m *Matrix) View(row string) (*Matrix, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := parseIndexSpec(row, m.GetRowNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUniqueIndex(rows); err != nil {
+		return nil, err
+	}
+
+	return m.selectValue(rows, nil, true), nil
+}
+
+//same as View but with an arbitrary list of rows
+func (m *Matrix) ViewRows(rows []int) (*Matrix, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := resolveIndexList(rows, m.GetRowNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUniqueIndex(rows); err != nil {
+		return nil, err
+	}
+
+	return m.selectValue(rows, nil, true), nil
+}
+
+///////////////////////////
+////////ELEMENT-WISE//////
+//////////////////////////
+
+//SubtractMatrix, MultiplyElementWise and DivideMatrix store the result in m
+//Apply, Exp, Sqrt, Abs and Clip return the result as a new matrix
+
+//combine every element of m with the element of m2 with the same index
+//the result is stored in m
+func (m *Matrix) calculateMatrix(m2 *Matrix, op func(float64, float64) float64) {
+	rows, rows2 := m.rowSlices(), m2.rowSlices()
+	parallelRows(len(rows), m.GetColumnNumber(), func(from, to int) {
+		for i := from; i < to; i++ {
+			for j, x := range rows2[i] {
+				rows[i][j] = op(rows[i][j], x)
+			}
+		}
+	})
+}
+
+//subtract m2 from m
+//as validation: dimension of both should agree
+func (m *Matrix) SubtractMatrix(m2 *Matrix) error {
+	if err := m.validateSameDimension(m2); err != nil {
+		return err
+	}
+
+	m.calculateMatrix(m2, func(a, b float64) float64 { return a - b })
+	return nil
+}
+
+//multiply every element with the element of m2 with the same index (Hadamard product)
+//as validation: dimension of both should agree
+func (m *Matrix) MultiplyElementWise(m2 *Matrix) error {
+	if err := m.validateSameDimension(m2); err != nil {
+		return err
+	}
+
+	m.calculateMatrix(m2, func(a, b float64) float64 { return a * b })
+	return nil
+}
+
+//divide every element by the element of m2 with the same index
+//dividing by 0 yields +Inf, -Inf or NaN just like float64 does
+func (m *Matrix) DivideMatrix(m2 *Matrix) error {
+	if err := m.validateSameDimension(m2); err != nil {
+		return err
+	}
+
+	m.calculateMatrix(m2, func(a, b float64) float64 { return a / b })
+	return nil
+}
+
+//unlike Calculate, Apply doesn't modify m but returns a new matrix
+func (m *Matrix) Apply(op func(float64) float64) *Matrix {
+	res := m.Clone()
+	res.Calculate(op)
+	return res
+}
+
+//get e^x of every element as a new matrix
+func (m *Matrix) Exp() *Matrix {
+	return m.Apply(math.Exp)
+}
+
+//get the square root of every element as a new matrix
+func (m *Matrix) Sqrt() *Matrix {
+	return m.Apply(math.Sqrt)
+}
+
+//get the absolute value of every element as a new matrix
+func (m *Matrix) Abs() *Matrix {
+	return m.Apply(math.Abs)
+}
+
+//limit every element to low...high and return the result as a new matrix
+func (m *Matrix) Clip(low, high float64) (*Matrix, error) {
+	if low > high {
+		return nil, fmt.Errorf("Lower limit should not be greater than upper")
+	}
+
+	return m.Apply(func(x float64) float64 {
+		return math.Min(math.Max(x, low), high)
+	}), nil
+}
+
+///////////////////////////
+////////BROADCASTING//////
+//////////////////////////
+
+//combine every row of m with the row vector v
+//e.g. subtract the column means from every row:
+//m.BroadcastRowVector(m.ColumnMeans(), func(x, mean float64) float64 { return x - mean })
+//as validation: v's length should be the same as number of columns
+func (m *Matrix) BroadcastRowVector(v *Vector, op func(float64, float64) float64) error {
+	if v.GetLength() != m.GetColumnNumber() {
+		return fmt.Errorf("Vector length must be the same as number of columns")
+	}
+
+	rows := m.rowSlices()
+	parallelRows(len(rows), m.GetColumnNumber(), func(from, to int) {
+		for _, row := range rows[from:to] {
+			for j, x := range row {
+				row[j] = op(x, v.val[j])
+			}
+		}
+	})
+	return nil
+}
+
+//combine every column of m with the column vector v
+//i.e. every element of row i is combined with the ith element of v
+//as validation: v's length should be the same as number of rows
+func (m *Matrix) BroadcastColumnVector(v *Vector, op func(float64, float64) float64) error {
+	if v.GetLength() != m.GetRowNumber() {
+		return fmt.Errorf("Vector length must be the same as number of rows")
+	}
+
+	rows := m.rowSlices()
+	parallelRows(len(rows), m.GetColumnNumber(), func(from, to int) {
+		for i := from; i < to; i++ {
+			for j, x := range rows[i] {
+				rows[i][j] = op(x, v.val[i])
+			}
+		}
+	})
+	return nil
+}
+
+//get the mean of every column as a row vector
+func (m *Matrix) ColumnMeans() *Vector {
+	res := NewZeroVector(m.GetColumnNumber())
+	for _, row := range m.rowSlices() {
+		for j, x := range row {
+			res.val[j] += x
+		}
+	}
+
+	res.MultiplyVariable(1 / float64(m.GetRowNumber()))
+	return res
+}
+
+//get the mean of every row as a column vector
+func (m *Matrix) RowMeans() *Vector {
+	res := NewZeroVector(m.GetRowNumber())
+	for i, row := range m.rowSlices() {
+		for _, x := range row {
+			res.val[i] += x
+		}
+	}
+
+	res.MultiplyVariable(1 / float64(m.GetColumnNumber()))
+	return res
+}
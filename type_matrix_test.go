@@ -2,6 +2,7 @@ package ml
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -288,3 +289,205 @@ func TestAddOnesVectorToFirstMatrix(t *testing.T) {
 	assert.Equal(t, float64(2), m.getSingleValue(2, 4))
 
 }
+
+func TestSliceMatrix(t *testing.T) {
+	input := [][]float64{
+		[]float64{1, 2, 3},
+		[]float64{4, 5, 6},
+		[]float64{7, 8, 9},
+		[]float64{10, 11, 12},
+	}
+	m, _ := NewMatrix(input)
+
+	_, err := m.Slice("5", ":")
+	assert.Error(t, err)
+
+	s, err := m.Slice("2:", "-2:")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, s.GetRowNumber())
+	assert.Equal(t, 2, s.GetColumnNumber())
+	assert.Equal(t, float64(5), s.getSingleValue(1, 1))
+	assert.Equal(t, float64(12), s.getSingleValue(3, 2))
+
+	s, err = m.Slice("::2", "3")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{3, 9}, s.getColumnVector(1).val)
+
+	//slice is a copy
+	s.setSingleValue(1, 1, 100)
+	assert.Equal(t, float64(3), m.getSingleValue(1, 3))
+}
+
+func TestSelectRowsAndColumnsMatrix(t *testing.T) {
+	input := [][]float64{
+		[]float64{1, 2, 3},
+		[]float64{4, 5, 6},
+		[]float64{7, 8, 9},
+	}
+	m, _ := NewMatrix(input)
+
+	_, err := m.SelectRows([]int{1, 4})
+	assert.Error(t, err)
+
+	r, err := m.SelectRows([]int{3, 1, -1})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, r.GetRowNumber())
+	assert.Equal(t, []float64{7, 8, 9}, r.getRowVector(1).val)
+	assert.Equal(t, []float64{1, 2, 3}, r.getRowVector(2).val)
+	assert.Equal(t, []float64{7, 8, 9}, r.getRowVector(3).val)
+
+	c, err := m.SelectColumns([]int{3, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{6, 4}, c.getRowVector(2).val)
+
+	_, err = m.MaskRows([]bool{true})
+	assert.Error(t, err)
+
+	_, err = m.MaskRows([]bool{false, false, false})
+	assert.Equal(t, ErrEmptyMatrix, err)
+
+	all, err := m.Slice("-100:100", ":1000000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, m.rowSlices(), all.rowSlices())
+	_, err = m.Slice("100:", ":")
+	assert.Error(t, err)
+
+	mr, err := m.MaskRows([]bool{false, true, true})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mr.GetRowNumber())
+	assert.Equal(t, float64(4), mr.getSingleValue(1, 1))
+
+	_, err = m.DropColumns(1, 2, 3)
+	assert.Error(t, err)
+
+	d, err := m.DropColumns(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{7, 9}, d.getRowVector(3).val)
+}
+
+func TestViewAndCloneMatrix(t *testing.T) {
+	input := [][]float64{
+		[]float64{1, 2},
+		[]float64{3, 4},
+		[]float64{5, 6},
+	}
+	m, _ := NewMatrix(input)
+
+	v, err := m.View("2:")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v.GetRowNumber())
+
+	//view shares the row vectors with m
+	v.setSingleValue(1, 1, 30)
+	assert.Equal(t, float64(30), m.getSingleValue(2, 1))
+
+	vr, err := m.ViewRows([]int{-1})
+	assert.NoError(t, err)
+	vr.setSingleValue(1, 2, 60)
+	assert.Equal(t, float64(60), m.getSingleValue(3, 2))
+
+	//the same row can't be viewed twice, -1 and 3 are the same row
+	_, err = m.ViewRows([]int{1, 1})
+	assert.Equal(t, ErrDuplicateIndex, err)
+	_, err = m.ViewRows([]int{-1, 3})
+	assert.Equal(t, ErrDuplicateIndex, err)
+
+	//but copies can
+	sr, err := m.SelectRows([]int{1, 1})
+	assert.NoError(t, err)
+	sr.AddVariable(1)
+	assert.Equal(t, []float64{2, 3}, sr.getRowVector(2).val)
+	assert.Equal(t, float64(1), m.getSingleValue(1, 1))
+
+	//clone doesn't
+	c := m.Clone()
+	c.setSingleValue(1, 1, 10)
+	assert.Equal(t, float64(1), m.getSingleValue(1, 1))
+}
+
+func TestElementWiseMatrix(t *testing.T) {
+	input := [][]float64{
+		[]float64{2, 4, 6},
+		[]float64{1, 9, 3},
+	}
+	m, _ := NewMatrix(input)
+
+	wrong := NewConstantMatrix(3, 2, 1)
+	assert.Error(t, m.SubtractMatrix(wrong))
+	assert.Error(t, m.MultiplyElementWise(wrong))
+	assert.Error(t, m.DivideMatrix(wrong))
+
+	m2, _ := NewMatrix([][]float64{
+		[]float64{1, 2, 3},
+		[]float64{1, 3, 1},
+	})
+
+	err := m.MultiplyElementWise(m2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{2, 8, 18}, m.getRowVector(1).val)
+
+	err = m.DivideMatrix(m2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 9, 3}, m.getRowVector(2).val)
+
+	err = m.SubtractMatrix(m2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 3}, m.getRowVector(1).val)
+	assert.Equal(t, []float64{0, 6, 2}, m.getRowVector(2).val)
+}
+
+func TestApplyAndMathMatrix(t *testing.T) {
+	m, _ := NewMatrix([][]float64{
+		[]float64{-4, 0, 9},
+	})
+
+	res := m.Apply(func(x float64) float64 { return x * 2 })
+	assert.Equal(t, []float64{-8, 0, 18}, res.getRowVector(1).val)
+	assert.Equal(t, []float64{-4, 0, 9}, m.getRowVector(1).val)
+
+	//none of them modifies m
+	abs := m.Abs()
+	assert.Equal(t, []float64{4, 0, 9}, abs.getRowVector(1).val)
+
+	sqrt := abs.Sqrt()
+	assert.Equal(t, []float64{2, 0, 3}, sqrt.getRowVector(1).val)
+
+	_, err := sqrt.Clip(3, 1)
+	assert.Error(t, err)
+	clip, err := sqrt.Clip(1, 2.5)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{2, 1, 2.5}, clip.getRowVector(1).val)
+	assert.Equal(t, []float64{2, 0, 3}, sqrt.getRowVector(1).val)
+
+	assert.Equal(t, math.E, clip.Exp().getSingleValue(1, 2))
+	assert.Equal(t, []float64{-4, 0, 9}, m.getRowVector(1).val)
+}
+
+func TestBroadcastMatrix(t *testing.T) {
+	m, _ := NewMatrix([][]float64{
+		[]float64{1, 10},
+		[]float64{3, 20},
+		[]float64{5, 60},
+	})
+
+	means := m.ColumnMeans()
+	assert.Equal(t, []float64{3, 30}, means.val)
+	assert.Equal(t, []float64{5.5, 11.5, 32.5}, m.RowMeans().val)
+
+	sub := func(x, y float64) float64 { return x - y }
+	assert.Error(t, m.BroadcastRowVector(NewZeroVector(3), sub))
+	assert.Error(t, m.BroadcastColumnVector(NewZeroVector(2), sub))
+
+	//center the columns
+	err := m.BroadcastRowVector(means, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{-2, 0, 2}, m.getColumnVector(1).val)
+	assert.Equal(t, []float64{-20, -10, 30}, m.getColumnVector(2).val)
+
+	//scale every row by its own factor
+	err = m.BroadcastColumnVector(NewVector([]float64{1, 2, 0.5}), func(x, y float64) float64 { return x * y })
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{-2, -20}, m.getRowVector(1).val)
+	assert.Equal(t, []float64{0, -20}, m.getRowVector(2).val)
+	assert.Equal(t, []float64{1, 15}, m.getRowVector(3).val)
+}
@@ -0,0 +1,402 @@
+package ml
+
+import (
+	"fmt"
+	"sort"
+)
+
+type (
+	//COOMatrix stores a sparse matrix as a list of (row, col, value) triplets
+	//it's cheap to build element by element and should be converted
+	//into a SparseMatrix for calculation
+	COOMatrix struct {
+		numRow, numCol int
+		rows, cols     []int
+		val            []float64
+	}
+
+	//SparseMatrix stores only the non-zero values in compressed sparse row (CSR) format:
+	//the values of row i are val[rowPtr[i-1]:rowPtr[i]] with the columns in colIdx
+	//internally all indices are 0-based, but all functions use 1-based ones like Matrix
+	SparseMatrix struct {
+		numRow, numCol int
+		rowPtr         []int
+		colIdx         []int
+		val            []float64
+	}
+)
+
+///////////////////////////
+////////NEW SPARSE////////
+//////////////////////////
+
+//create a new empty COO matrix
+func NewCOOMatrix(numRow, numCol int) (*COOMatrix, error) {
+	if numRow < 1 || numCol < 1 {
+		return nil, ErrEmptyMatrix
+	}
+
+	return &COOMatrix{numRow: numRow, numCol: numCol}, nil
+}
+
+//add a value at row and col (both 1-based)
+//values added to the same position more than once are summed up
+func (c *COOMatrix) Add(row, col int, val float64) error {
+	if row < 1 || row > c.numRow || col < 1 || col > c.numCol {
+		return ErrOutOfRange
+	}
+
+	c.rows = append(c.rows, row-1)
+	c.cols = append(c.cols, col-1)
+	c.val = append(c.val, val)
+	return nil
+}
+
+//convert into CSR, duplicates are summed up and zeros are dropped
+func (c *COOMatrix) ToCSR() *SparseMatrix {
+	order := make([]int, len(c.val))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if c.rows[order[a]] != c.rows[order[b]] {
+			return c.rows[order[a]] < c.rows[order[b]]
+		}
+		return c.cols[order[a]] < c.cols[order[b]]
+	})
+
+	s := &SparseMatrix{numRow: c.numRow, numCol: c.numCol, rowPtr: make([]int, c.numRow+1)}
+	for key, i := range order {
+		r, col, val := c.rows[i], c.cols[i], c.val[i]
+
+		//merge with the previous triplet if it has the same position
+		if key > 0 && c.rows[order[key-1]] == r && c.cols[order[key-1]] == col {
+			s.val[len(s.val)-1] += val
+			continue
+		}
+
+		s.colIdx = append(s.colIdx, col)
+		s.val = append(s.val, val)
+		s.rowPtr[r+1]++
+	}
+
+	//rowPtr contains the number of elements per row so far
+	for i := 1; i <= s.numRow; i++ {
+		s.rowPtr[i] += s.rowPtr[i-1]
+	}
+
+	s.dropZeros()
+	return s
+}
+
+//create a new sparse matrix out of triplets, indices are 1-based
+//as validation all three slices should have the same length
+func NewSparseMatrix(numRow, numCol int, rows, cols []int, val []float64) (*SparseMatrix, error) {
+	if len(rows) != len(cols) || len(rows) != len(val) {
+		return nil, fmt.Errorf("Length of rows, cols and values should be the same")
+	}
+
+	c, err := NewCOOMatrix(numRow, numCol)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range val {
+		if err := c.Add(rows[key], cols[key], val[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.ToCSR(), nil
+}
+
+//convert a dense matrix into a sparse one, only non-zero values are stored
+func NewSparseMatrixFromMatrix(m *Matrix) (*SparseMatrix, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	s := &SparseMatrix{numRow: m.GetRowNumber(), numCol: m.GetColumnNumber(), rowPtr: []int{0}}
+	for _, row := range m.rowSlices() {
+		for j, x := range row {
+			if x != 0 {
+				s.colIdx = append(s.colIdx, j)
+				s.val = append(s.val, x)
+			}
+		}
+		s.rowPtr = append(s.rowPtr, len(s.val))
+	}
+
+	return s, nil
+}
+
+//remove explicitly stored zeros e.g. after duplicates have been summed up to 0
+func (s *SparseMatrix) dropZeros() {
+	var colIdx []int
+	var val []float64
+	rowPtr := []int{0}
+
+	for i := 0; i < s.numRow; i++ {
+		for k := s.rowPtr[i]; k < s.rowPtr[i+1]; k++ {
+			if s.val[k] != 0 {
+				colIdx = append(colIdx, s.colIdx[k])
+				val = append(val, s.val[k])
+			}
+		}
+		rowPtr = append(rowPtr, len(val))
+	}
+
+	s.rowPtr, s.colIdx, s.val = rowPtr, colIdx, val
+}
+
+///////////////////////////
+////////GET DATA//////////
+//////////////////////////
+
+func (s *SparseMatrix) GetRowNumber() int    { return s.numRow }
+func (s *SparseMatrix) GetColumnNumber() int { return s.numCol }
+
+//get the number of stored (non-zero) values
+func (s *SparseMatrix) NonZero() int { return len(s.val) }
+
+//get a single value, row and col are 1-based
+func (s *SparseMatrix) GetSingleValue(row, col int) (float64, error) {
+	if row < 1 || row > s.numRow || col < 1 || col > s.numCol {
+		return 0, ErrOutOfRange
+	}
+
+	//the columns of a row are sorted
+	from, to := s.rowPtr[row-1], s.rowPtr[row]
+	k := from + sort.SearchInts(s.colIdx[from:to], col-1)
+	if k < to && s.colIdx[k] == col-1 {
+		return s.val[k], nil
+	}
+
+	return 0, nil
+}
+
+//convert into a dense matrix
+func (s *SparseMatrix) ToMatrix() *Matrix {
+	m := NewZeroMatrix(s.numRow, s.numCol)
+	for i := 0; i < s.numRow; i++ {
+		row := m.getRowVector(i + 1).val
+		for k := s.rowPtr[i]; k < s.rowPtr[i+1]; k++ {
+			row[s.colIdx[k]] = s.val[k]
+		}
+	}
+	return m
+}
+
+func (s *SparseMatrix) String() string {
+	sprint := fmt.Sprintf("Num Column: %d\nNum Row: %d\nNon-zero: %d\n",
+		s.numCol, s.numRow, s.NonZero())
+
+	for i := 0; i < s.numRow; i++ {
+		for k := s.rowPtr[i]; k < s.rowPtr[i+1]; k++ {
+			sprint += fmt.Sprintf("(%d, %d): %.2f\n", i+1, s.colIdx[k]+1, s.val[k])
+		}
+	}
+
+	return sprint
+}
+
+///////////////////////////
+////////CALCULATION///////
+//////////////////////////
+
+//transpose by counting the elements of every column first
+func (s *SparseMatrix) Transpose() *SparseMatrix {
+	t := &SparseMatrix{
+		numRow: s.numCol,
+		numCol: s.numRow,
+		rowPtr: make([]int, s.numCol+1),
+		colIdx: make([]int, len(s.val)),
+		val:    make([]float64, len(s.val))}
+
+	for _, c := range s.colIdx {
+		t.rowPtr[c+1]++
+	}
+	for i := 1; i <= t.numRow; i++ {
+		t.rowPtr[i] += t.rowPtr[i-1]
+	}
+
+	//rows of s are visited in order, so the columns of t stay sorted
+	next := copyInts(t.rowPtr)
+	for i := 0; i < s.numRow; i++ {
+		for k := s.rowPtr[i]; k < s.rowPtr[i+1]; k++ {
+			c := s.colIdx[k]
+			t.colIdx[next[c]] = i
+			t.val[next[c]] = s.val[k]
+			next[c]++
+		}
+	}
+
+	return t
+}
+
+func copyInts(input []int) []int {
+	result := make([]int, len(input))
+	copy(result, input)
+	return result
+}
+
+//multiply with a column vector, the result has one element per row
+func (s *SparseMatrix) multiplyVector(v []float64) []float64 {
+	result := make([]float64, s.numRow)
+	parallelRows(s.numRow, s.NonZero()/s.numRow+1, func(from, to int) {
+		for i := from; i < to; i++ {
+			var sum float64
+			for k := s.rowPtr[i]; k < s.rowPtr[i+1]; k++ {
+				sum += s.val[k] * v[s.colIdx[k]]
+			}
+			result[i] = sum
+		}
+	})
+	return result
+}
+
+func (s *SparseMatrix) MultiplyVector(v *Vector) (*Vector, error) {
+	if v.GetLength() != s.numCol {
+		return nil, fmt.Errorf("Vector length must be the same as number of columns")
+	}
+
+	return NewVector(s.multiplyVector(v.val)), nil
+}
+
+//multiply the transposed matrix with a column vector without transposing it
+//the result has one element per column
+func (s *SparseMatrix) multiplyTransposeVector(v []float64) []float64 {
+	result := make([]float64, s.numCol)
+	for i := 0; i < s.numRow; i++ {
+		for k := s.rowPtr[i]; k < s.rowPtr[i+1]; k++ {
+			result[s.colIdx[k]] += s.val[k] * v[i]
+		}
+	}
+	return result
+}
+
+//multiply two sparse matrices, the result is sparse as well
+//number of s's col and s2's row must agree
+func (s *SparseMatrix) Multiply(s2 *SparseMatrix) (*SparseMatrix, error) {
+	if s.numCol != s2.numRow {
+		return nil, fmt.Errorf("First column and second row dimenstions don't agree")
+	}
+
+	res := &SparseMatrix{numRow: s.numRow, numCol: s2.numCol, rowPtr: []int{0}}
+
+	//accumulate every row of the result in a dense slice
+	//and remember which columns have been touched
+	acc := make([]float64, s2.numCol)
+	used := make([]bool, s2.numCol)
+	for i := 0; i < s.numRow; i++ {
+		var cols []int
+		for k := s.rowPtr[i]; k < s.rowPtr[i+1]; k++ {
+			a, row := s.val[k], s.colIdx[k]
+			for k2 := s2.rowPtr[row]; k2 < s2.rowPtr[row+1]; k2++ {
+				c := s2.colIdx[k2]
+				if !used[c] {
+					used[c] = true
+					cols = append(cols, c)
+				}
+				acc[c] += a * s2.val[k2]
+			}
+		}
+
+		sort.Ints(cols)
+		for _, c := range cols {
+			if acc[c] != 0 {
+				res.colIdx = append(res.colIdx, c)
+				res.val = append(res.val, acc[c])
+			}
+			acc[c], used[c] = 0, false
+		}
+		res.rowPtr = append(res.rowPtr, len(res.val))
+	}
+
+	return res, nil
+}
+
+//multiply with a dense matrix, the result is dense
+func (s *SparseMatrix) MultiplyMatrix(m *Matrix) (*Matrix, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	if s.numCol != m.GetRowNumber() {
+		return nil, fmt.Errorf("First column and second row dimenstions don't agree")
+	}
+
+	b := m.rowSlices()
+	res := NewZeroMatrix(s.numRow, m.GetColumnNumber())
+	rows := res.rowSlices()
+	parallelRows(s.numRow, (s.NonZero()/s.numRow+1)*m.GetColumnNumber(), func(from, to int) {
+		for i := from; i < to; i++ {
+			for k := s.rowPtr[i]; k < s.rowPtr[i+1]; k++ {
+				a, bk := s.val[k], b[s.colIdx[k]]
+				for j, x := range bk {
+					rows[i][j] += a * x
+				}
+			}
+		}
+	})
+
+	return res, nil
+}
+
+///////////////////////////
+////////REGRESSION////////
+//////////////////////////
+
+//calculate theta1 + sigma(2..n)thetaj*xj-1 for every row of a sparse matrix
+//the 1's column is not stored in x
+func sparseLinearCombination(x *SparseMatrix, theta []float64) []float64 {
+	result := x.multiplyVector(theta[1:])
+	for i := range result {
+		result[i] += theta[0]
+	}
+	return result
+}
+
+//calculate the gradient of the regression cost functions on a sparse matrix
+//residual i is h(theta*xi) - yi and the formula is the same as derivTheta:
+//1/m * (sigma(1...m)(residuali * xij) + lambda * thetaj) with no regularization for j = 1
+func sparseGradient(x *SparseMatrix, y, theta *Vector, lambda float64, h func(float64) float64) *Vector {
+	m := float64(y.GetLength())
+
+	res := sparseLinearCombination(x, theta.val)
+	for i := range res {
+		res[i] = h(res[i]) - y.val[i]
+	}
+
+	var sum float64
+	for _, r := range res {
+		sum += r
+	}
+
+	grad := append([]float64{sum}, x.multiplyTransposeVector(res)...)
+	for j := range grad {
+		if j > 0 {
+			grad[j] += lambda * theta.val[j]
+		}
+		grad[j] = grad[j] / m
+	}
+
+	return NewVector(grad)
+}
+
+//validate the input of a regression model trained on a sparse matrix
+func validateSparseRegressionInput(x *SparseMatrix, y, theta *Vector, alpha float64) error {
+	if x.GetRowNumber() != y.GetLength() {
+		return fmt.Errorf("X and Y row number are not the same")
+	}
+
+	if x.GetColumnNumber()+1 != theta.GetLength() {
+		return fmt.Errorf("Number of X and theta features are not the same")
+	}
+
+	if alpha <= 0 {
+		return fmt.Errorf("Learning rate alpha should be greater than 0")
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package ml
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSparseMatrix() *SparseMatrix {
+	//[0 2 0]
+	//[1 0 0]
+	//[0 0 0]
+	//[0 4 3]
+	s, _ := NewSparseMatrix(4, 3,
+		[]int{4, 1, 2, 4, 4},
+		[]int{2, 2, 1, 3, 2},
+		[]float64{1, 2, 1, 3, 3})
+	return s
+}
+
+func TestNewSparseMatrix(t *testing.T) {
+	_, err := NewSparseMatrix(2, 2, []int{1}, []int{1, 2}, []float64{1})
+	assert.Error(t, err)
+
+	_, err = NewSparseMatrix(2, 2, []int{3}, []int{1}, []float64{1})
+	assert.Equal(t, ErrOutOfRange, err)
+
+	_, err = NewCOOMatrix(0, 2)
+	assert.Error(t, err)
+
+	s := newTestSparseMatrix()
+	assert.Equal(t, 4, s.GetRowNumber())
+	assert.Equal(t, 3, s.GetColumnNumber())
+	assert.Equal(t, 4, s.NonZero())
+
+	//duplicates are summed up
+	val, err := s.GetSingleValue(4, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4), val)
+
+	val, err = s.GetSingleValue(3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), val)
+
+	_, err = s.GetSingleValue(5, 1)
+	assert.Error(t, err)
+
+	//zeros after summing up are not stored
+	c, _ := NewCOOMatrix(2, 2)
+	c.Add(1, 1, 5)
+	c.Add(1, 1, -5)
+	c.Add(2, 2, 1)
+	assert.Equal(t, 1, c.ToCSR().NonZero())
+	assert.Equal(t, ErrOutOfRange, c.Add(3, 1, 1))
+
+	fmt.Println(s)
+}
+
+func TestSparseMatrixConversion(t *testing.T) {
+	s := newTestSparseMatrix()
+
+	m := s.ToMatrix()
+	assert.Equal(t, []float64{0, 2, 0}, m.getRowVector(1).val)
+	assert.Equal(t, []float64{0, 0, 0}, m.getRowVector(3).val)
+	assert.Equal(t, []float64{0, 4, 3}, m.getRowVector(4).val)
+
+	s2, err := NewSparseMatrixFromMatrix(m)
+	assert.NoError(t, err)
+	assert.Equal(t, s, s2)
+
+	_, err = NewSparseMatrixFromMatrix(&Matrix{})
+	assert.Error(t, err)
+
+	tr := s.Transpose()
+	dense, _ := m.Transpose()
+	assert.Equal(t, dense, tr.ToMatrix())
+	assert.Equal(t, s, tr.Transpose())
+}
+
+func TestSparseMatrixMultiply(t *testing.T) {
+	s := newTestSparseMatrix()
+	m := s.ToMatrix()
+
+	_, err := s.MultiplyVector(NewZeroVector(2))
+	assert.Error(t, err)
+
+	v := NewVector([]float64{1, 2, 3})
+	res, err := s.MultiplyVector(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{4, 1, 0, 17}, res.val)
+
+	//sparse * dense
+	_, err = s.MultiplyMatrix(m)
+	assert.Error(t, err)
+
+	mt, _ := m.Transpose()
+	expected, _ := m.Multiply(mt)
+	resm, err := s.MultiplyMatrix(mt)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, resm)
+
+	//sparse * sparse
+	_, err = s.Multiply(s)
+	assert.Error(t, err)
+
+	ress, err := s.Multiply(s.Transpose())
+	assert.NoError(t, err)
+	assert.Equal(t, expected, ress.ToMatrix())
+	assert.Equal(t, 5, ress.NonZero())
+}
+
+func TestSparseLinearRegression(t *testing.T) {
+	file := "data3.csv"
+	x, _ := LoadNewMatrix(file, "1:80", "1")
+	y, _ := LoadNewVector(file, "1:80", "2")
+	sx, _ := NewSparseMatrixFromMatrix(x)
+
+	_, err := NewSparseLinearRegression(sx, y, NewZeroVector(3), 0.01)
+	assert.Error(t, err)
+
+	slr, err := NewSparseLinearRegression(sx, y, NewZeroVector(2), 0.01)
+	assert.NoError(t, err)
+	slr.AddRegularizationFactor(1)
+
+	lr, _ := NewLinearRegression(x, y, NewZeroVector(2), 0.01)
+	lr.AddRegularizationFactor(1)
+
+	//sparse and dense training should be the same
+	slr.UpdateGrad(100)
+	lr.UpdateGrad(100)
+	assert.InDeltaSlice(t, lr.theta.val, slr.theta.val, 1e-9)
+	assert.InDelta(t, lr.CostFunc(), slr.CostFunc(), 1e-9)
+
+	xverif, _ := LoadNewMatrix(file, "81:100", "1")
+	sxverif, _ := NewSparseMatrixFromMatrix(xverif)
+	expected, _ := lr.Snapshot().Predict(xverif)
+	res, err := slr.Snapshot().PredictSparse(sxverif)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, expected.val, res.val, 1e-9)
+}
+
+func TestSparseLogisticRegression(t *testing.T) {
+	//one-hot like features, most of the values are zero
+	c, _ := NewCOOMatrix(8, 4)
+	var labels []float64
+	for i := 1; i <= 8; i++ {
+		c.Add(i, (i-1)%4+1, 1)
+		if i%4 < 2 {
+			labels = append(labels, 1)
+		} else {
+			labels = append(labels, 0)
+		}
+	}
+	sx := c.ToCSR()
+	y := NewVector(labels)
+
+	_, err := NewSparseLogisticRegression(sx, NewConstantVector(8, 2), NewZeroVector(5), 1)
+	assert.Error(t, err)
+
+	slr, err := NewSparseLogisticRegression(sx, y, NewZeroVector(5), 1)
+	assert.NoError(t, err)
+
+	lr, _ := NewLogisticRegression(sx.ToMatrix(), y, NewZeroVector(5), 1)
+	assert.InDelta(t, lr.CostFunc(), slr.CostFunc(), 1e-12)
+	assert.InDeltaSlice(t, lr.CalculateGrad().val, slr.CalculateGrad().val, 1e-12)
+
+	slr.UpdateGrad(500, false)
+	proba, err := slr.Snapshot().PredictSparseProba(sx)
+	assert.NoError(t, err)
+	for i := 1; i <= y.GetLength(); i++ {
+		assert.Equal(t, y.getSingleValue(i) == 1, proba.getSingleValue(i) > 0.5)
+	}
+}
@@ -9,6 +9,11 @@ import (
 )
 
 type (
+	//a function which returns a *Vector never modifies the receiver
+	//but returns a new vector e.g. Exp, Add, Select or Normalize
+	//a function which returns nothing or only an error modifies the
+	//receiver in place e.g. Log, AddVariable, AddVector or Calculate
+	//Matrix follows the same rule
 	Vector struct {
 		val []float64
 	}
@@ -277,3 +282,241 @@ func (v *Vector) DotProduct(v2 *Vector) (float64, error) {
 
 	return v.dotProduct(v2), nil
 }
+
+///////////////////////////
+////////SELECTION/////////
+//////////////////////////
+
+//select elements using the same syntax as LoadNewVector's row
+//e.g. Slice("1:80") or Slice("-20:") or Slice("::2")
+//the result is a copy of the selected values
+func (v *Vector) Slice(spec string) (*Vector, error) {
+	idx, err := parseIndexSpec(spec, v.GetLength())
+	if err != nil {
+		return nil, err
+	}
+
+	return v.selectIndex(idx), nil
+}
+
+//copy the elements of the already resolved indices into a new vector
+func (v *Vector) selectIndex(idx []int) *Vector {
+	result := make([]float64, 0, len(idx))
+	for _, i := range idx {
+		result = append(result, v.getSingleValue(i))
+	}
+	return NewVector(result)
+}
+
+//select an arbitrary list of elements (1-based, negative counts from the end)
+func (v *Vector) Select(idx []int) (*Vector, error) {
+	idx, err := resolveIndexList(idx, v.GetLength())
+	if err != nil {
+		return nil, err
+	}
+
+	return v.selectIndex(idx), nil
+}
+
+//select all elements where mask is true
+//length of mask should be the same as the vector's length
+//a mask which selects nothing returns ErrEmptyVector, same as Matrix.MaskRows
+func (v *Vector) Mask(mask []bool) (*Vector, error) {
+	if len(mask) != v.GetLength() {
+		return nil, ErrVectorFalseDimension
+	}
+
+	var result []float64
+	for key, ok := range mask {
+		if ok {
+			result = append(result, v.val[key])
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, ErrEmptyVector
+	}
+
+	return NewVector(result), nil
+}
+
+///////////////////////////
+////////IMMUTABLE/////////
+//////////////////////////
+
+//unlike the calculator functions these never modify the vector
+//but return a new one, so they can be chained e.g. v.Pow(2).Scale(0.5)
+//e.g. v.Log() takes the log of v in place while v.Exp() returns e^v
+
+//create a copy of the vector
+func (v *Vector) Clone() *Vector {
+	return NewVector(copyFloats(v.val))
+}
+
+//apply op on every element and return the result as a new vector
+func (v *Vector) Map(op func(float64) float64) *Vector {
+	res := v.Clone()
+	res.Calculate(op)
+	return res
+}
+
+//multiply every element with n
+func (v *Vector) Scale(n float64) *Vector {
+	return v.Map(v.multiplyVar(n))
+}
+
+//get every element to the power of n
+func (v *Vector) Pow(n float64) *Vector {
+	return v.Map(v.powerOf(n))
+}
+
+//get e^x of every element
+func (v *Vector) Exp() *Vector {
+	return v.Map(math.Exp)
+}
+
+//combine every element with the element of v2 with the same index
+//as validation both dimensions must agree
+func (v *Vector) mapVector(v2 *Vector, op func(float64, float64) float64) (*Vector, error) {
+	if v.GetLength() != v2.GetLength() {
+		return nil, ErrVectorFalseDimension
+	}
+
+	res := v.Clone()
+	res.CalculateVector(func(x float64, i int) float64 {
+		return op(x, v2.getSingleValue(i))
+	})
+	return res, nil
+}
+
+//get v + v2
+func (v *Vector) Add(v2 *Vector) (*Vector, error) {
+	return v.mapVector(v2, func(a, b float64) float64 { return a + b })
+}
+
+//get v - v2
+func (v *Vector) Sub(v2 *Vector) (*Vector, error) {
+	return v.mapVector(v2, func(a, b float64) float64 { return a - b })
+}
+
+//multiply element-wise
+func (v *Vector) Mul(v2 *Vector) (*Vector, error) {
+	return v.mapVector(v2, func(a, b float64) float64 { return a * b })
+}
+
+///////////////////////////
+////////NORM//////////////
+//////////////////////////
+
+//L1 norm is sigma(1...n)|vi|
+func (v *Vector) NormL1() float64 {
+	var result float64
+	for _, x := range v.val {
+		result += math.Abs(x)
+	}
+	return result
+}
+
+//L2 (euclidean) norm is sqrt(sigma(1...n)vi^2)
+func (v *Vector) NormL2() float64 {
+	return math.Sqrt(v.dotProduct(v))
+}
+
+//L-infinity norm is the greatest absolute value
+func (v *Vector) NormInf() float64 {
+	var result float64
+	for _, x := range v.val {
+		result = math.Max(result, math.Abs(x))
+	}
+	return result
+}
+
+//scale the vector so its L2 norm is 1
+//return error if the norm is 0
+func (v *Vector) Normalize() (*Vector, error) {
+	norm := v.NormL2()
+	if norm == 0 {
+		return nil, fmt.Errorf("Vector with norm 0 can't be normalized")
+	}
+
+	return v.Scale(1 / norm), nil
+}
+
+//cosine similarity is v.v2 / (|v| * |v2|) between -1 and 1
+//return error if one of the vectors has norm 0
+func (v *Vector) CosineSimilarity(v2 *Vector) (float64, error) {
+	if v.GetLength() != v2.GetLength() {
+		return 0, ErrVectorFalseDimension
+	}
+
+	norm := v.NormL2() * v2.NormL2()
+	if norm == 0 {
+		return 0, fmt.Errorf("Cosine similarity is not defined for vectors with norm 0")
+	}
+
+	return v.dotProduct(v2) / norm, nil
+}
+
+///////////////////////////
+////////DISTANCE//////////
+//////////////////////////
+
+//euclidean distance is |v1 - v2| using the L2 norm
+func EuclideanDistance(v1, v2 *Vector) (float64, error) {
+	diff, err := v1.Sub(v2)
+	if err != nil {
+		return 0, err
+	}
+
+	return diff.NormL2(), nil
+}
+
+//manhattan distance is |v1 - v2| using the L1 norm
+func ManhattanDistance(v1, v2 *Vector) (float64, error) {
+	diff, err := v1.Sub(v2)
+	if err != nil {
+		return 0, err
+	}
+
+	return diff.NormL1(), nil
+}
+
+//chebyshev distance is |v1 - v2| using the L-infinity norm
+func ChebyshevDistance(v1, v2 *Vector) (float64, error) {
+	diff, err := v1.Sub(v2)
+	if err != nil {
+		return 0, err
+	}
+
+	return diff.NormInf(), nil
+}
+
+//minkowski distance is (sigma(1...n)|v1i - v2i|^p)^(1/p)
+//p = 1 is the manhattan and p = 2 the euclidean distance
+//p should be at least 1, otherwise it's not a metric
+func MinkowskiDistance(v1, v2 *Vector, p float64) (float64, error) {
+	if p < 1 {
+		return 0, fmt.Errorf("Minkowski distance needs p >= 1")
+	}
+
+	diff, err := v1.Sub(v2)
+	if err != nil {
+		return 0, err
+	}
+
+	var result float64
+	for _, x := range diff.val {
+		result += math.Pow(math.Abs(x), p)
+	}
+	return math.Pow(result, 1/p), nil
+}
+
+//cosine distance is 1 - cosine similarity, between 0 and 2
+func CosineDistance(v1, v2 *Vector) (float64, error) {
+	sim, err := v1.CosineSimilarity(v2)
+	if err != nil {
+		return 0, err
+	}
+
+	return 1 - sim, nil
+}
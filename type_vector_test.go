@@ -156,3 +156,126 @@ func TestMultiplyVector(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, v1.val, v3.val)
 }
+
+func TestSliceVector(t *testing.T) {
+	v := NewVector([]float64{1, 2, 3, 4, 5})
+
+	_, err := v.Slice("6")
+	assert.Error(t, err)
+
+	s, err := v.Slice("-2:")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{4, 5}, s.val)
+
+	s, err = v.Select([]int{5, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{5, 1}, s.val)
+
+	_, err = v.Mask([]bool{true})
+	assert.Error(t, err)
+
+	//same as Matrix.MaskRows a mask can't select nothing
+	_, err = v.Mask(make([]bool, 5))
+	assert.Equal(t, ErrEmptyVector, err)
+
+	s, err = v.Slice("-100:100")
+	assert.NoError(t, err)
+	assert.Equal(t, v.val, s.val)
+
+	s, err = v.Mask([]bool{true, false, true, false, true})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 3, 5}, s.val)
+
+	//selection is a copy
+	s.setSingleValue(1, 10)
+	assert.Equal(t, float64(1), v.getSingleValue(1))
+}
+
+func TestImmutableVector(t *testing.T) {
+	v := NewVector([]float64{1, 2, 3})
+
+	res := v.Pow(2).Scale(0.5).Map(func(x float64) float64 { return x + 1 })
+	assert.Equal(t, []float64{1.5, 3, 5.5}, res.val)
+	assert.Equal(t, []float64{1, 2, 3}, v.val)
+
+	c := v.Clone()
+	c.setSingleValue(1, 10)
+	assert.Equal(t, float64(1), v.getSingleValue(1))
+
+	e := NewZeroVector(2).Exp()
+	assert.Equal(t, []float64{1, 1}, e.val)
+
+	v2 := NewVector([]float64{3, 2, 1})
+	sum, err := v.Add(v2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{4, 4, 4}, sum.val)
+
+	diff, err := v.Sub(v2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{-2, 0, 2}, diff.val)
+
+	prod, err := v.Mul(v2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{3, 4, 3}, prod.val)
+
+	_, err = v.Add(NewZeroVector(2))
+	assert.Equal(t, ErrVectorFalseDimension, err)
+	_, err = EuclideanDistance(v, NewZeroVector(2))
+	assert.Equal(t, ErrVectorFalseDimension, err)
+
+	assert.Equal(t, []float64{1, 2, 3}, v.val)
+	assert.Equal(t, []float64{3, 2, 1}, v2.val)
+}
+
+func TestVectorNorm(t *testing.T) {
+	v := NewVector([]float64{3, -4})
+
+	assert.Equal(t, float64(7), v.NormL1())
+	assert.Equal(t, float64(5), v.NormL2())
+	assert.Equal(t, float64(4), v.NormInf())
+
+	n, err := v.Normalize()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.6, -0.8}, n.val, 1e-12)
+
+	_, err = NewZeroVector(2).Normalize()
+	assert.Error(t, err)
+
+	sim, err := v.CosineSimilarity(NewVector([]float64{-6, 8}))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(-1), sim)
+
+	_, err = v.CosineSimilarity(NewZeroVector(2))
+	assert.Error(t, err)
+}
+
+func TestVectorDistance(t *testing.T) {
+	v1 := NewVector([]float64{1, 2, 3})
+	v2 := NewVector([]float64{4, 6, 3})
+
+	d, err := EuclideanDistance(v1, v2)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(5), d)
+
+	d, err = ManhattanDistance(v1, v2)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(7), d)
+
+	d, err = ChebyshevDistance(v1, v2)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4), d)
+
+	d, err = MinkowskiDistance(v1, v2, 2)
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(5), d, 1e-12)
+
+	_, err = MinkowskiDistance(v1, v2, 0.5)
+	assert.Error(t, err)
+
+	d, err = CosineDistance(v1, v1.Scale(3))
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(0), d, 1e-12)
+
+	_, err = EuclideanDistance(v1, NewZeroVector(2))
+	assert.Equal(t, ErrVectorFalseDimension, err)
+}
@@ -1,8 +1,10 @@
 package ml
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -31,55 +33,144 @@ func convertCSVToFloat64(rec [][]string) ([][]float64, error) {
 	return result, nil
 }
 
-//use row/col ":" if all data should be fetched
-//otherwise either using 1 for e.g. row 1 only
-//or 1:2 to fetch row 1-2
-func vectorCat(cat string) ([]int, error) {
-	//first case is check whether cat is == ":"
-	//if that's the case then simply return empty slice and no error
-	if cat == ":" {
-		return []int{}, nil
-	}
-
-	//second case is to determine whether cat is a range or single value
-	//split with ":", if len is 1 then single value, it's a range if 2, and error otherwise
-	splitted := strings.Split(cat, ":")
-	switch len(splitted) {
-	case 1:
-		//the result should be into int convertable
-		if res, err := strconv.Atoi(splitted[0]); err != nil {
+//resolve a single index against a dimension with n elements
+//indices are 1-based, negative indices count from the end (-1 is the last element)
+func resolveIndex(idx, n int) (int, error) {
+	if idx < 0 {
+		idx = n + idx + 1
+	}
+
+	if idx < 1 || idx > n {
+		return 0, ErrOutOfRange
+	}
+
+	return idx, nil
+}
+
+//resolve one end of a range, unlike a single index it may lie outside of 1...n
+//it is clamped later by clampRange, so "81:100" on 97 elements selects 81...97
+func resolveRangeEnd(idx, n int) (int, error) {
+	if idx == 0 {
+		return 0, ErrOutOfRange
+	}
+
+	if idx < 0 {
+		return n + idx + 1, nil
+	}
+
+	return idx, nil
+}
+
+//clamp both ends of a range so the walk from start to stop never leaves 0...n+1
+//an end beyond the elements is moved to the first or last one, the same as slicing in python
+//e.g. "-100:3" selects 1...3 and "1:1000000" selects 1...n, a range starting
+//behind the elements in walking direction selects nothing
+func clampRange(start, stop, step, n int) (int, int) {
+	clamp := func(i, low, high int) int {
+		if i < low {
+			return low
+		}
+		if i > high {
+			return high
+		}
+		return i
+	}
+
+	if step > 0 {
+		return clamp(start, 1, n+1), clamp(stop, 0, n)
+	}
+	return clamp(start, 0, n), clamp(stop, 1, n+1)
+}
+
+//parse an index specification for a dimension with n elements
+//and return the selected indices (1-based) in order
+//supported syntax:
+//":" or "" selects everything
+//"3" selects a single index, "-1" selects the last one
+//"1:80" selects a range, both ends are included and clamped to 1...n
+//"5:" or ":5" leave one end open
+//"1:10:2" selects every 2nd index, a negative step walks backwards e.g. "::-1"
+func parseIndexSpec(spec string, n int) ([]int, error) {
+	spec = strings.TrimSpace(spec)
+
+	splitted := strings.Split(spec, ":")
+	if len(splitted) > 3 {
+		return nil, ErrInvalidIndexSpec
+	}
+
+	//a single value is just an index
+	if len(splitted) == 1 {
+		//empty spec means all indices
+		if splitted[0] == "" {
+			return parseIndexSpec(":", n)
+		}
+
+		idx, err := strconv.Atoi(splitted[0])
+		if err != nil {
 			return nil, err
-		} else {
-			if res < 1 {
-				return nil, ErrOutOfRange
-			}
+		}
 
-			return []int{res}, nil
+		res, err := resolveIndex(idx, n)
+		if err != nil {
+			return nil, err
 		}
-	case 2:
-		//now both of the value should be into int convertable
-		var result []int
-		for _, s := range splitted {
-			if res, err := strconv.Atoi(s); err != nil {
-				return nil, err
-			} else {
-				if res < 1 {
-					return nil, ErrOutOfRange
-				}
 
-				result = append(result, res)
-			}
+		return []int{res}, nil
+	}
+
+	//get the step first since it determines the default values of both ends
+	step := 1
+	if len(splitted) == 3 && splitted[2] != "" {
+		s, err := strconv.Atoi(splitted[2])
+		if err != nil {
+			return nil, err
+		}
+		if s == 0 {
+			return nil, fmt.Errorf("Step of index specification can't be 0")
+		}
+		step = s
+	}
+
+	start, stop := 1, n
+	if step < 0 {
+		start, stop = n, 1
+	}
+
+	if splitted[0] != "" {
+		idx, err := strconv.Atoi(splitted[0])
+		if err != nil {
+			return nil, err
 		}
+		if start, err = resolveRangeEnd(idx, n); err != nil {
+			return nil, err
+		}
+	}
 
-		//also return error if result[0] is not lesser than result[1]
-		if result[0] >= result[1] {
-			return nil, fmt.Errorf("Upper limit should be greater than lower")
+	if splitted[1] != "" {
+		idx, err := strconv.Atoi(splitted[1])
+		if err != nil {
+			return nil, err
+		}
+		if stop, err = resolveRangeEnd(idx, n); err != nil {
+			return nil, err
 		}
+	}
+
+	start, stop = clampRange(start, stop, step, n)
 
-		return result, nil
+	var result []int
+	for i := start; (step > 0 && i <= stop) || (step < 0 && i >= stop); i += step {
+		if i >= 1 && i <= n {
+			result = append(result, i)
+		}
+	}
+
+	//a range which selects nothing is most likely a mistake
+	if len(result) == 0 {
+		return nil, fmt.Errorf("Index specification %q selects nothing", spec)
 	}
-	//if the result is neither 1 nor 2 then return error
-	return nil, fmt.Errorf("Invalid category syntax")
+
+	return result, nil
 }
 
 //select particular row and column from a [][]float64
@@ -91,68 +182,73 @@ func filterInputByCat(input [][]float64, row, col string) ([][]float64, error) {
 
 	//get the desired rows and cols
 	//return if there's any error
-	//get all result if rows/cols is an empty slice
-	rows, err := vectorCat(row)
+	rows, err := parseIndexSpec(row, len(input))
 	if err != nil {
 		return nil, err
 	}
 
-	cols, err := vectorCat(col)
+	cols, err := parseIndexSpec(col, len(input[0]))
 	if err != nil {
 		return nil, err
 	}
 
-	//first get the rows
-	rowsV := [][]float64{}
-	switch len(rows) {
-	case 0:
-		rowsV = input
-	case 1:
-		//validate the input to prevent panic
-		if len(input) < rows[0] {
-			return nil, ErrOutOfRange
-		}
-		//append only the selected row
-		rowsV = append(rowsV, input[rows[0]-1])
-	case 2:
-		for k, r := range input {
-			if (k+1) >= rows[0] && (k+1) <= rows[1] {
-				rowsV = append(rowsV, r)
-			}
-		}
-	}
-
-	//now filter the columns
 	result := [][]float64{}
-	switch len(cols) {
-	case 0:
-		result = rowsV
-	case 1:
-		//validate the input to prevent panic
-		if len(rowsV) == 0 || len(rowsV[0]) < cols[0] {
-			return nil, ErrOutOfRange
-		}
-		//append only the selected row
-		for _, flt := range rowsV {
-			result = append(result, []float64{flt[cols[0]-1]})
-		}
-	case 2:
-		for _, flt := range rowsV {
-			var f []float64
-			for k, c := range flt {
-				if (k+1) >= cols[0] && (k+1) <= cols[1] {
-					f = append(f, c)
-				}
-			}
-			result = append(result, f)
+	for _, r := range rows {
+		f := make([]float64, 0, len(cols))
+		for _, c := range cols {
+			f = append(f, input[r-1][c-1])
 		}
+		result = append(result, f)
 	}
 
 	return result, nil
 }
 
+//copy a slice of float64 so modifying the result doesn't change the input
+func copyFloats(input []float64) []float64 {
+	result := make([]float64, len(input))
+	copy(result, input)
+	return result
+}
+
 //function to call the sigmoid func
 //sigmoid is defined as g(z) = 1 / (1 + e^(-z))
 func sigm(z float64) float64 {
 	return 1 / (1 + math.Pow(math.E, -1*z))
 }
+
+//call step itr times (or until ctx is done if itr < 0) as long as ctx is not done
+//return the number of finished steps and ctx.Err() if ctx is done
+//itr < 0 needs a ctx which can be done, otherwise the training would never stop
+func trainContext(ctx context.Context, itr int, step func()) (int, error) {
+	if itr < 0 && ctx.Done() == nil {
+		return 0, fmt.Errorf("Training without iteration limit needs a cancellable context")
+	}
+
+	for i := 0; itr < 0 || i < itr; i++ {
+		select {
+		case <-ctx.Done():
+			return i, ctx.Err()
+		default:
+		}
+
+		step()
+	}
+
+	return itr, nil
+}
+
+//get the distinct values in ascending order
+func uniqueSorted(val []float64) []float64 {
+	seen := make(map[float64]bool)
+	var result []float64
+	for _, f := range val {
+		if !seen[f] {
+			seen[f] = true
+			result = append(result, f)
+		}
+	}
+
+	sort.Float64s(result)
+	return result
+}
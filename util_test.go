@@ -1,41 +1,99 @@
 package ml
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestVectorCat(t *testing.T) {
-	cat := ":"
+func TestParseIndexSpec(t *testing.T) {
+	res, err := parseIndexSpec(":", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(res))
+
+	_, err = parseIndexSpec("a", 10)
+	assert.Error(t, err)
+
+	res, err = parseIndexSpec("10", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10}, res)
 
-	res, err := vectorCat(cat)
+	_, err = parseIndexSpec("11", 10)
+	assert.Error(t, err)
+
+	_, err = parseIndexSpec("0", 10)
+	assert.Error(t, err)
+
+	res, err = parseIndexSpec("1:5", 10)
 	assert.NoError(t, err)
-	assert.Equal(t, 0, len(res))
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, res)
 
-	cat = "a"
-	_, err = vectorCat(cat)
+	_, err = parseIndexSpec("1:a", 10)
 	assert.Error(t, err)
 
-	cat = "10"
-	res, err = vectorCat(cat)
+	_, err = parseIndexSpec("5:3", 10)
+	assert.Error(t, err)
+
+	res, err = parseIndexSpec("8:", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{8, 9, 10}, res)
+
+	res, err = parseIndexSpec(":3", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, res)
+
+	res, err = parseIndexSpec("-1", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10}, res)
+
+	res, err = parseIndexSpec("-3:", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{8, 9, 10}, res)
+
+	res, err = parseIndexSpec("1:10:3", 10)
 	assert.NoError(t, err)
-	assert.Equal(t, 10, res[0])
+	assert.Equal(t, []int{1, 4, 7, 10}, res)
 
-	cat = "1:5"
-	res, err = vectorCat(cat)
+	res, err = parseIndexSpec("::-4", 10)
 	assert.NoError(t, err)
-	assert.Equal(t, 1, res[0])
-	assert.Equal(t, 5, res[1])
+	assert.Equal(t, []int{10, 6, 2}, res)
 
-	cat = "1:a"
-	_, err = vectorCat(cat)
+	_, err = parseIndexSpec("1:3:0", 10)
 	assert.Error(t, err)
 
-	cat = "1:3:5"
-	_, err = vectorCat(cat)
+	_, err = parseIndexSpec("1:3:5:7", 10)
 	assert.Error(t, err)
+
+	//ends beyond the elements are clamped without walking through them
+	res, err = parseIndexSpec("8:1000000000000", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{8, 9, 10}, res)
+
+	res, err = parseIndexSpec("-1000000000000:3", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, res)
+
+	res, err = parseIndexSpec("-15:10:3", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 7, 10}, res)
+
+	res, err = parseIndexSpec("1000000000000:-1000000000000:-4", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10, 6, 2}, res)
+
+	//a range which lies completely outside selects nothing
+	_, err = parseIndexSpec("1000000000000:", 10)
+	assert.Error(t, err)
+	_, err = parseIndexSpec(":-1000000000000", 10)
+	assert.Error(t, err)
+	_, err = parseIndexSpec("-1000000000000::-1", 10)
+	assert.Error(t, err)
+
+	//a single index is not clamped
+	_, err = parseIndexSpec("-11", 10)
+	assert.Equal(t, ErrOutOfRange, err)
 }
 
 func TestConvertCSVToFloat64(t *testing.T) {
@@ -90,6 +148,10 @@ func TestFilterInputByCat(t *testing.T) {
 	assert.Equal(t, 2, len(res))
 	assert.Equal(t, 2, len(res[0]))
 	assert.Equal(t, float64(4), res[1][0])
+
+	res, err = filterInputByCat(input, "-1", "::2")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{[]float64{0, 1}}, res)
 }
 
 func TestSigmoidFunc(t *testing.T) {
@@ -99,3 +161,25 @@ func TestSigmoidFunc(t *testing.T) {
 	res = sigm(1)
 	assert.Equal(t, "0.73", fmt.Sprintf("%.2f", res))
 }
+
+func TestTrainContext(t *testing.T) {
+	var steps int
+	step := func() { steps++ }
+
+	n, err := trainContext(context.Background(), 5, step)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, 5, steps)
+
+	//stop as soon as ctx is canceled, even without a limit
+	ctx, cancel := context.WithCancel(context.Background())
+	steps = 0
+	n, err = trainContext(ctx, -1, func() {
+		steps++
+		if steps == 3 {
+			cancel()
+		}
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 3, n)
+}